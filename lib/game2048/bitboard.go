@@ -0,0 +1,239 @@
+package game2048
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Bitboard is a 4x4 board in 64 bits for fast search and simulation, the
+// element of row r, column c is the 4 bits at 4*(4*r+c). Two tiles of
+// 2^MaxBitboardElement would merge beyond 4 bits so they never do, boards
+// holding one are not taken from Game or NewBitboard. Below that, moves and
+// spawns give the same boards and scores as Game.
+type Bitboard uint64
+
+const (
+	// BitboardSide of the only board size a Bitboard holds
+	BitboardSide = 4
+	// MaxBitboardElement is the largest element of a Bitboard
+	MaxBitboardElement = 0xF
+	rowMask            = 0xFFFF
+)
+
+// tables of every row, indexed by its 16 bits
+var (
+	rowLeft, rowRight [1 << 16]uint16
+	rowScore          [1 << 16]int
+	rowEmpty          [1 << 16]int
+	rowCanMerge       [1 << 16]bool
+)
+
+func init() {
+	for row := 0; row < 1<<16; row++ {
+		var line [BitboardSide]int
+		for c := range line {
+			line[c] = row >> (4 * c) & MaxBitboardElement
+			if line[c] == emptyElement {
+				rowEmpty[row]++
+			}
+			if c > 0 && line[c] != emptyElement && line[c] == line[c-1] && line[c] < MaxBitboardElement {
+				rowCanMerge[row] = true
+			}
+		}
+		line, rowScore[row] = slideLine(line)
+		for c, e := range line {
+			rowLeft[row] |= uint16(e) << (4 * c)
+		}
+	}
+	for row := 0; row < 1<<16; row++ {
+		rowRight[row] = reverseRow(rowLeft[reverseRow(uint16(row))])
+	}
+}
+
+// slideLine to column 0 as Game does, a tile merges once per move
+func slideLine(line [BitboardSide]int) ([BitboardSide]int, int) {
+	var result [BitboardSide]int
+	n, score, merged := 0, 0, false
+	for _, e := range line {
+		if e == emptyElement {
+			continue
+		}
+		if n > 0 && !merged && result[n-1] == e && e < MaxBitboardElement {
+			result[n-1]++
+			score += 1 << (e + 1)
+			merged = true
+			continue
+		}
+		result[n] = e
+		n++
+		merged = false
+	}
+	return result, score
+}
+
+func reverseRow(row uint16) uint16 {
+	return row>>12 | row>>4&0x00F0 | row<<4&0x0F00 | row<<12
+}
+
+// NewBitboard of tile values by row from the top, 0 for empty
+func NewBitboard(board [][]int) (Bitboard, error) {
+	if len(board) != BitboardSide {
+		return 0, fmt.Errorf("bitboard should have %d rows, not %d", BitboardSide, len(board))
+	}
+	b := Bitboard(0)
+	for i, row := range board {
+		if len(row) != BitboardSide {
+			return 0, fmt.Errorf("bitboard should have %d columns, not %d", BitboardSide, len(row))
+		}
+		for j, tile := range row {
+			e := element(tile)
+			if tile != 0 && (tile != 1<<e || e >= MaxBitboardElement) {
+				return 0, fmt.Errorf("tile %d is not a power of 2 below 2^%d", tile, MaxBitboardElement)
+			}
+			b = b.Set(i, j, e)
+		}
+	}
+	return b, nil
+}
+
+// Bitboard of g, false unless g is 4x4 with tiles below 2^MaxBitboardElement
+func (g *Game) Bitboard() (Bitboard, bool) {
+	if g.width != BitboardSide || g.height != BitboardSide {
+		return 0, false
+	}
+	b := Bitboard(0)
+	for pos, e := range g.board {
+		if e >= MaxBitboardElement {
+			return 0, false
+		}
+		b |= Bitboard(e) << (4 * pos)
+	}
+	return b, true
+}
+
+// Element in row i, column j
+func (b Bitboard) Element(i, j int) int {
+	return int(b>>(4*(BitboardSide*i+j))) & MaxBitboardElement
+}
+
+// Set element in row i, column j
+func (b Bitboard) Set(i, j, e int) Bitboard {
+	shift := 4 * (BitboardSide*i + j)
+	return b&^(MaxBitboardElement<<shift) | Bitboard(e)<<shift
+}
+
+// Row i as 4 elements of 4 bits, column 0 in the lowest bits
+func (b Bitboard) Row(i int) uint16 {
+	return uint16(b >> (16 * i) & rowMask)
+}
+
+// Board is the tile values by row from the top, 0 for empty
+func (b Bitboard) Board() [][]int {
+	board := make([][]int, BitboardSide)
+	for i := range board {
+		board[i] = make([]int, BitboardSide)
+		for j := range board[i] {
+			if e := b.Element(i, j); e != emptyElement {
+				board[i][j] = 1 << e
+			}
+		}
+	}
+	return board
+}
+
+// Transpose rows into columns
+func (b Bitboard) Transpose() Bitboard {
+	a1 := b & 0xF0F00F0FF0F00F0F
+	a2 := b & 0x0000F0F00000F0F0
+	a3 := b & 0x0F0F00000F0F0000
+	a := a1 | a2<<12 | a3>>12
+	b1 := a & 0xFF00FF0000FF00FF
+	b2 := a & 0x00FF00FF00000000
+	b3 := a & 0x00000000FF00FF00
+	return b1 | b2>>24 | b3<<24
+}
+
+func (b Bitboard) moveRows(table *[1 << 16]uint16) (Bitboard, int) {
+	moved, score := Bitboard(0), 0
+	for i := 0; i < BitboardSide; i++ {
+		row := b.Row(i)
+		moved |= Bitboard(table[row]) << (16 * i)
+		score += rowScore[row]
+	}
+	return moved, score
+}
+
+// Move all tiles to direction without new numbers, the board is unchanged
+// if no tile can move. gained is the score of merges.
+func (b Bitboard) Move(direction Direction) (moved Bitboard, gained int) {
+	switch direction {
+	case Up:
+		moved, gained = b.Transpose().moveRows(&rowLeft)
+		return moved.Transpose(), gained
+	case Down:
+		moved, gained = b.Transpose().moveRows(&rowRight)
+		return moved.Transpose(), gained
+	case Left:
+		return b.moveRows(&rowLeft)
+	default:
+		return b.moveRows(&rowRight)
+	}
+}
+
+// Empty cells
+func (b Bitboard) Empty() int {
+	n := 0
+	for i := 0; i < BitboardSide; i++ {
+		n += rowEmpty[b.Row(i)]
+	}
+	return n
+}
+
+// Alive until no tile can move
+func (b Bitboard) Alive() bool {
+	if b.Empty() > 0 {
+		return true
+	}
+	t := b.Transpose()
+	for i := 0; i < BitboardSide; i++ {
+		if rowCanMerge[b.Row(i)] || rowCanMerge[t.Row(i)] {
+			return true
+		}
+	}
+	return false
+}
+
+// Spawn up to policy.PerMove new numbers drawn from r, a Game of the same
+// random state spawns the same ones. Adversarial policies spawn randomly.
+func (b Bitboard) Spawn(r *rand.Rand, policy SpawnPolicy) Bitboard {
+	total := 0
+	for _, w := range policy.Weights {
+		total += w
+	}
+	for n := policy.PerMove; n > 0; n-- {
+		free := b.Empty()
+		if free == 0 {
+			break
+		}
+		e, k := len(policy.Weights), r.Intn(total)
+		for i, w := range policy.Weights {
+			if k < w {
+				e = i + 1
+				break
+			}
+			k -= w
+		}
+		k = r.Intn(free)
+		for pos := 0; pos < BitboardSide*BitboardSide; pos++ {
+			if b>>(4*pos)&MaxBitboardElement != emptyElement {
+				continue
+			}
+			if k == 0 {
+				b |= Bitboard(e) << (4 * pos)
+				break
+			}
+			k--
+		}
+	}
+	return b
+}
@@ -0,0 +1,156 @@
+package game2048
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+// random 4x4 board of elements up to maxElement, a cell is empty with
+// chance 1/emptyOdds
+func randomElements(r *rand.Rand, maxElement, emptyOdds int) []int {
+	board := make([]int, BitboardSide*BitboardSide)
+	for pos := range board {
+		if r.Intn(emptyOdds) != 0 {
+			board[pos] = 1 + r.Intn(maxElement)
+		}
+	}
+	return board
+}
+
+func TestNewBitboard(t *testing.T) {
+	board := [][]int{
+		{2, 0, 0, 4},
+		{0, 8, 0, 0},
+		{0, 0, 16384, 0},
+		{1024, 0, 0, 2},
+	}
+	b, err := NewBitboard(board)
+	assert.Nil(t, err)
+	assert.Equal(t, board, b.Board())
+	assert.Equal(t, 14, b.Element(2, 2))
+	assert.Equal(t, 10, b.Empty())
+	assert.Equal(t, b, b.Transpose().Transpose())
+	assert.Equal(t, b.Element(3, 0), b.Transpose().Element(0, 3))
+
+	for _, board := range [][][]int{
+		{{2, 0, 0, 0}},
+		{{2, 0, 0}, {0, 0, 0}, {0, 0, 0}, {0, 0, 0}},
+		{{3, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}},
+		{{32768, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}},
+	} {
+		_, err := NewBitboard(board)
+		assert.NotNil(t, err, "%v", board)
+	}
+
+	g, _ := New(Options{Width: 4, Height: 3, Difficult: 2})
+	_, ok := g.Bitboard()
+	assert.False(t, ok, "only 4x4 games")
+
+	// two 2^15 merge to 2^16 in Game, beyond 4 bits
+	g = newTestGame(BitboardSide, BitboardSide, []int{
+		15, 15, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+	})
+	_, ok = g.Bitboard()
+	assert.False(t, ok, "no 2^15 tiles")
+}
+
+// moves of Bitboard and Game give the same boards and scores
+func TestBitboard_Move(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		board := randomElements(r, MaxBitboardElement, 3)
+		for _, direction := range []Direction{Up, Down, Left, Right} {
+			g := newTestGame(BitboardSide, BitboardSide, board)
+			b, ok := g.Bitboard()
+			if hasElement(board, MaxBitboardElement) {
+				assert.False(t, ok, "%v", board)
+				continue
+			}
+			assert.True(t, ok)
+			moved, gained := b.Move(direction)
+
+			g.lastMoveValid = false
+			g.slide(g.offset(direction))
+			// merges of 2^14 reach 2^15, which Game.Bitboard leaves out
+			if !assert.Equal(t, g.board, elements(moved), "%v moved %v", board, direction) {
+				return
+			}
+			assert.Equal(t, g.score, gained)
+			assert.Equal(t, g.lastMoveValid, moved != b)
+		}
+	}
+}
+
+func elements(b Bitboard) []int {
+	board := make([]int, 0, BitboardSide*BitboardSide)
+	for i := 0; i < BitboardSide; i++ {
+		for j := 0; j < BitboardSide; j++ {
+			board = append(board, b.Element(i, j))
+		}
+	}
+	return board
+}
+
+func hasElement(board []int, e int) bool {
+	for _, element := range board {
+		if element == e {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBitboard_Alive(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	dead := 0
+	for i := 0; i < 2000; i++ {
+		// few numbers and empty cells leave some boards dead
+		g := newTestGame(BitboardSide, BitboardSide, randomElements(r, 4, 50))
+		b, _ := g.Bitboard()
+		assert.Equal(t, g.Alive(), b.Alive(), "%v", g.board)
+		assert.Equal(t, g.boardFree, b.Empty())
+		if !g.Alive() {
+			dead++
+		}
+	}
+	assert.Greater(t, dead, 0)
+}
+
+// seeded games of Game and Bitboard stay the same to the end
+func TestBitboard_Spawn(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		opts := DefaultOptions()
+		opts.Seed = seed
+		if seed%2 == 0 {
+			opts.Spawn, _ = SpawnPreset("hard")
+		}
+		g, err := New(opts)
+		assert.Nil(t, err)
+		b, _ := g.Bitboard()
+		source := rng.NewSource(0)
+		source.SetState(g.rngSource.State())
+		r, moves := rng.New(source), rand.New(rand.NewSource(seed))
+
+		for g.Alive() {
+			direction := Direction(moves.Intn(4))
+			_, gained := g.Move(direction)
+			moved, bitGained := b.Move(direction)
+			if moved != b {
+				moved = moved.Spawn(r, g.SpawnPolicy())
+			}
+			b = moved
+			want, _ := g.Bitboard()
+			if !assert.Equal(t, want, b, "seed %d", seed) {
+				return
+			}
+			assert.Equal(t, gained, bitGained)
+			assert.Equal(t, g.Alive(), b.Alive())
+		}
+	}
+}
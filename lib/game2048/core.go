@@ -1,66 +1,92 @@
 package game2048
 
-import "math/rand"
+import (
+	"math/rand"
+	"time"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/rng"
+)
 
 const (
 	emptyElement = 0
 	firstElement = 0
 )
 
+// limits of board size and difficult, a new number is 2^1 to 2^difficult
+const (
+	minSide      = 3
+	maxSide      = 8
+	maxDifficult = 4
+	// largest target is 2^maxTargetElement
+	maxTargetElement = 20
+)
+
 // Game implement Game interface
 type Game struct {
 	score int
-	fps   int
 
 	board                 []int
 	height, width         int
 	up, down, left, right int
-	difficult             int
+	difficult, target     int
 	alive                 bool
 	boardFree             int
 	lastMoveValid         bool
 	lastNewNumberIndex    int
+	spawned               []int // positions of new numbers of last move
+	rngSource             *rng.Source
+	rng                   *rand.Rand
+	seed                  int64
+	fixedSeed             bool
+	undoLimit, undosUsed  int
+	history               []undoState
+	spawnPolicy           SpawnPolicy
 }
 
 // ============== Main Progress ================
 
 // if we assum width=4, height=4
 // the g.board is a 1-demension slice like
-//  [0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15]
+//
+//	[0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15]
+//
 // but we can regard it as follow
-//  [
-//    0,  1,  2,  3,
-//    4,  5,  6,  7,
-//    8,  9, 10, 11,
-//    12, 13, 14, 15
-//  ]
+//
+//	[
+//	  0,  1,  2,  3,
+//	  4,  5,  6,  7,
+//	  8,  9, 10, 11,
+//	  12, 13, 14, 15
+//	]
+//
 // and we can judge the index by following equation:
-//  index = width * row_index + column_index
-//  e.g. g.board[2][1] = 4 * 2 + 1 = 9 = g.board[9]
+//
+//	index = width * row_index + column_index
+//	e.g. g.board[2][1] = 4 * 2 + 1 = 9 = g.board[9]
 //
 // so the 4 direction can be defined as integer
-//  UP: 	width * -1
-//  DOWN: 	width
-//  LEFT: 	-1
-//  RIGHT:	1
-func (g *Game) init(w, h, difficult int) {
+//
+//	UP: 	width * -1
+//	DOWN: 	width
+//	LEFT: 	-1
+//	RIGHT:	1
+func (g *Game) init(w, h int) {
 	g.score = 0
 	g.lastMoveValid = false
-	g.fps = 60
-	g.difficult = difficult
+	g.undosUsed, g.history = 0, nil
 	g.width, g.height = w, h
 	g.up, g.down, g.left, g.right = -1*g.width, g.width, -1, 1
 	g.board = make([]int, g.width*g.height)
-
-	// init board with two numbers
-	pos1 := rand.Intn(len(g.board))
-	pos2 := rand.Intn(len(g.board) - 1)
-	if pos2 >= pos1 {
-		pos2++
+	g.boardFree = len(g.board)
+	if !g.fixedSeed {
+		g.seed = time.Now().UnixNano()
 	}
-	g.board[pos1] = rand.Intn(g.difficult) + 1
-	g.board[pos2] = rand.Intn(g.difficult) + 1
-	g.boardFree = len(g.board) - 2
+	g.rngSource.Seed(g.seed)
+
+	// init board with initial numbers
+	g.spawned = nil
+	g.spawn(g.spawnPolicy.Initial)
+	g.checkAlive()
 }
 
 func (g *Game) moveOrMergeElement(pos, direction int) {
@@ -72,8 +98,7 @@ func (g *Game) moveOrMergeElement(pos, direction int) {
 	}
 
 	if g.board[newPos] == g.board[pos] { // can be merged
-		g.score += g.board[pos]
-		g.score += g.board[pos]
+		g.score += 1 << (g.board[pos] + 1) // value of the merged tile
 		g.board[newPos]++                  // promote the target element
 		g.board[newPos] = -g.board[newPos] // mark the target not be merged again
 		g.board[pos] = emptyElement
@@ -91,7 +116,16 @@ func (g *Game) moveOrMergeElement(pos, direction int) {
 
 func (g *Game) operate(direction int) {
 	g.lastMoveValid = false
+	g.spawned = nil
+	g.slide(direction)
+	if g.lastMoveValid {
+		g.spawn(g.spawnPolicy.PerMove)
+	}
+	g.checkAlive()
+}
 
+// slide move or merge every element to direction, without new numbers
+func (g *Game) slide(direction int) {
 	// first loop: move or merge
 	if direction == g.up || direction == g.left {
 		// fmt.Printf("direction:%d, normal traverse\n", direction)
@@ -105,26 +139,12 @@ func (g *Game) operate(direction int) {
 		}
 	}
 
-	// second loop: clean marks, judge alive, generate new number
-	newNumIndexCountDown := -1
-	if g.lastMoveValid {
-		newNumIndexCountDown = rand.Intn(g.boardFree) // choose a free space randomly for new number
-	}
+	// second loop: clean marks
 	for pos := firstElement; pos < len(g.board); pos++ {
 		if g.board[pos] < 0 {
 			g.board[pos] = -g.board[pos] // clean this mark by making it positive
-		} else if g.board[pos] == emptyElement {
-			if newNumIndexCountDown == 0 { // the free pos will be assign for new number
-				g.board[pos] = rand.Intn(g.difficult) + 1 // rand.Intn range from [0,n) while we need [1,n], so plus 1
-				g.boardFree--                             // free space decreased because of the new number
-			}
-			if newNumIndexCountDown >= 0 { // wait for next free pos
-				newNumIndexCountDown--
-			}
 		}
 	}
-
-	g.checkAlive()
 }
 
 func (g *Game) checkAlive() {
@@ -0,0 +1,23 @@
+package game2048
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGame_operate(t *testing.T) {
+	g := newTestGame(4, 4, []int{
+		1, 1, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 2,
+	})
+	g.operate(g.left)
+	assert.True(t, g.lastMoveValid)
+	assert.Greater(t, g.score, 0)
+	assert.Equal(t, 2, g.board[0])
+	assert.Equal(t, 2, g.board[12])
+	assert.Equal(t, 13, g.boardFree, "merged one, spawned one")
+	assert.True(t, g.alive)
+}
@@ -0,0 +1,210 @@
+package game2048
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/rng"
+)
+
+// defaultTarget is the tile to win when Options.Target is 0
+const defaultTarget = 2048
+
+// Direction to move all tiles to
+type Direction int
+
+// directions of Move
+const (
+	Up Direction = iota
+	Down
+	Left
+	Right
+)
+
+var directionNames = [...]string{Up: "up", Down: "down", Left: "left", Right: "right"}
+
+func (d Direction) String() string {
+	if d < Up || d > Right {
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+	return directionNames[d]
+}
+
+// Options of a new game
+type Options struct {
+	// board is Width x Height, 3x3 up to 8x8
+	Width, Height int
+	// new numbers are 2^1 up to 2^Difficult
+	Difficult int
+	// tile to win, a power of 2 above new numbers, 0 means 2048
+	Target int
+	// moves that can be taken back in a game, negative for unlimited
+	Undos int
+	// new numbers by policy, Difficult is ignored once Spawn.Weights is
+	// set, otherwise 2^1 up to 2^Difficult have equal chance
+	Spawn SpawnPolicy
+	// games of the same seed and moves are the same, 0 picks a seed
+	// from the clock for every game
+	Seed int64
+}
+
+// DefaultOptions is the classic 4x4 game, mostly 2s and a few 4s
+func DefaultOptions() Options {
+	spawn, _ := SpawnPreset("classic")
+	return Options{Width: 4, Height: 4, Difficult: 2, Target: defaultTarget, Undos: 1, Spawn: spawn}
+}
+
+// DailyOptions is the default game seeded by date, everyone playing on
+// the same (UTC) day gets the same board
+func DailyOptions(date time.Time) Options {
+	opts := DefaultOptions()
+	opts.Seed = DailySeed(date)
+	return opts
+}
+
+// DailySeed of date, such as 20261019
+func DailySeed(date time.Time) int64 {
+	y, m, d := date.UTC().Date()
+	return int64(y*10000 + int(m)*100 + d)
+}
+
+func (opts Options) spawnPolicy() SpawnPolicy {
+	if opts.Spawn.Weights == nil {
+		return uniformSpawn(opts.Difficult)
+	}
+	return opts.Spawn
+}
+
+func (opts Options) validate() error {
+	if opts.Width < minSide || opts.Width > maxSide || opts.Height < minSide || opts.Height > maxSide {
+		return fmt.Errorf("board %dx%d is out of %dx%d to %dx%d", opts.Width, opts.Height, minSide, minSide, maxSide, maxSide)
+	}
+	if opts.Spawn.Weights == nil && (opts.Difficult < 1 || opts.Difficult > maxDifficult) {
+		return fmt.Errorf("difficult %d is out of [1, %d]", opts.Difficult, maxDifficult)
+	}
+	spawn := opts.spawnPolicy()
+	if err := spawn.validate(opts.Width * opts.Height); err != nil {
+		return err
+	}
+	largest := 1 << len(spawn.Weights)
+	if opts.Target != 0 && (opts.Target <= largest || opts.Target > 1<<maxTargetElement ||
+		opts.Target&(opts.Target-1) != 0) {
+		return fmt.Errorf("target %d should be a power of 2 in (%d, %d]", opts.Target, largest, 1<<maxTargetElement)
+	}
+	return nil
+}
+
+// element of tile value, which is a power of 2
+func element(tile int) int {
+	e := 0
+	for ; tile > 1; tile >>= 1 {
+		e++
+	}
+	return e
+}
+
+// New game with initial numbers on board, it does not need termbox so bots,
+// tests and other frontends could drive it
+func New(opts Options) (*Game, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Target == 0 {
+		opts.Target = defaultTarget
+	}
+	g := &Game{target: element(opts.Target), undoLimit: opts.Undos}
+	g.spawnPolicy = opts.spawnPolicy()
+	g.spawnPolicy.Weights = append([]int(nil), g.spawnPolicy.Weights...)
+	g.difficult = len(g.spawnPolicy.Weights)
+	g.seed, g.fixedSeed = opts.Seed, opts.Seed != 0
+	g.rngSource = rng.NewSource(0)
+	g.rng = rng.New(g.rngSource)
+	g.init(opts.Width, opts.Height)
+	return g, nil
+}
+
+// Restart with a new board of the same options, a seeded game starts over
+// from the same board
+func (g *Game) Restart() {
+	g.init(g.width, g.height)
+}
+
+// SpawnPolicy of new numbers
+func (g *Game) SpawnPolicy() SpawnPolicy {
+	policy := g.spawnPolicy
+	policy.Weights = append([]int(nil), policy.Weights...)
+	return policy
+}
+
+// Seed of current game, New with it and the same moves replays the game
+func (g *Game) Seed() int64 {
+	return g.seed
+}
+
+func (g *Game) offset(direction Direction) int {
+	switch direction {
+	case Up:
+		return g.up
+	case Down:
+		return g.down
+	case Left:
+		return g.left
+	default:
+		return g.right
+	}
+}
+
+// Move all tiles to direction, a new number appears if any tile moved.
+// gained is the score of merges.
+func (g *Game) Move(direction Direction) (moved bool, gained int) {
+	score := g.score
+	g.pushHistory()
+	g.operate(g.offset(direction))
+	g.trimHistory()
+	return g.lastMoveValid, g.score - score
+}
+
+// Board is the tile values by row from the top, 0 for empty
+func (g *Game) Board() [][]int {
+	board := make([][]int, g.height)
+	for i := range board {
+		board[i] = make([]int, g.width)
+		for j := range board[i] {
+			if e := g.board[i*g.width+j]; e != emptyElement {
+				board[i][j] = 1 << e
+			}
+		}
+	}
+	return board
+}
+
+// Score of game, the value of every tile made by a merge
+func (g *Game) Score() int {
+	return g.score
+}
+
+// Alive until no tile can move
+func (g *Game) Alive() bool {
+	return g.alive
+}
+
+// Won once a tile reaches target
+func (g *Game) Won() bool {
+	for _, e := range g.board {
+		if e >= g.target {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone game, moves on the clone leave g untouched
+func (g *Game) Clone() *Game {
+	clone := *g
+	clone.board = append([]int(nil), g.board...)
+	clone.rngSource = rng.NewSource(0)
+	clone.rngSource.SetState(g.rngSource.State())
+	clone.rng = rng.New(clone.rngSource)
+	clone.history = append([]undoState(nil), g.history...)
+	return &clone
+}
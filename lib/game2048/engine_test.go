@@ -0,0 +1,167 @@
+package game2048
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// game with board of elements instead of the random start numbers
+func newTestGame(w, h int, board []int) *Game {
+	g, err := New(Options{Width: w, Height: h, Difficult: 2})
+	if err != nil {
+		panic(err)
+	}
+	copy(g.board, board)
+	g.boardFree = 0
+	for _, e := range board {
+		if e == emptyElement {
+			g.boardFree++
+		}
+	}
+	g.checkAlive()
+	return g
+}
+
+func TestNew(t *testing.T) {
+	g, err := New(DefaultOptions())
+	assert.Nil(t, err)
+	assert.True(t, g.Alive())
+	assert.False(t, g.Won())
+	count := 0
+	for _, row := range g.Board() {
+		assert.Len(t, row, 4)
+		for _, tile := range row {
+			if tile != 0 {
+				count++
+			}
+		}
+	}
+	assert.Equal(t, 2, count, "starts with two numbers")
+
+	_, err = New(Options{Width: 9, Height: 4, Difficult: 2})
+	assert.NotNil(t, err)
+}
+
+func TestGame_Move(t *testing.T) {
+	g := newTestGame(3, 3, []int{
+		1, 1, 2,
+		0, 0, 0,
+		0, 0, 0,
+	})
+	moved, gained := g.Move(Left)
+	assert.True(t, moved)
+	assert.Equal(t, 4, gained, "merges score the value of new tiles")
+	assert.Equal(t, gained, g.Score())
+	assert.Equal(t, []int{4, 4}, g.Board()[0][:2])
+
+	g = newTestGame(3, 3, []int{
+		10, 10, 0,
+		0, 0, 0,
+		0, 0, 0,
+	})
+	_, gained = g.Move(Right)
+	assert.Equal(t, 2048, gained)
+
+	g = newTestGame(3, 3, []int{
+		3, 4, 3,
+		4, 3, 4,
+		4, 3, 0,
+	})
+	assert.True(t, g.Alive())
+	moved, gained = g.Move(Left)
+	assert.False(t, moved)
+	assert.Equal(t, 0, gained)
+	moved, _ = g.Move(Right)
+	assert.True(t, moved)
+	assert.False(t, g.Alive(), "no tile can move on a full board")
+}
+
+func TestGame_Won(t *testing.T) {
+	g := newTestGame(3, 3, []int{
+		10, 10, 0,
+		0, 0, 0,
+		0, 0, 0,
+	})
+	assert.False(t, g.Won())
+	g.Move(Left)
+	assert.True(t, g.Won())
+	assert.Equal(t, 2048, g.Board()[0][0])
+}
+
+func TestGame_Clone(t *testing.T) {
+	g := newTestGame(3, 3, []int{
+		1, 1, 0,
+		0, 0, 0,
+		0, 0, 0,
+	})
+	clone := g.Clone()
+	clone.Move(Left)
+	assert.Equal(t, 0, g.Score())
+	assert.Equal(t, []int{2, 2, 0}, g.Board()[0])
+	assert.Equal(t, 4, clone.Board()[0][0])
+}
+
+func TestOptions_validate(t *testing.T) {
+	assert.Nil(t, Options{Width: 3, Height: 8, Difficult: 1}.validate())
+	assert.Nil(t, Options{Width: 4, Height: 4, Difficult: 2, Target: 8}.validate())
+	for _, opts := range []Options{
+		{Width: 2, Height: 4, Difficult: 2},
+		{Width: 4, Height: 9, Difficult: 2},
+		{Width: 4, Height: 4, Difficult: 0},
+		{Width: 4, Height: 4, Difficult: maxDifficult + 1},
+		{Width: 4, Height: 4, Difficult: 2, Target: 4},
+		{Width: 4, Height: 4, Difficult: 2, Target: 1000},
+		{Width: 4, Height: 4, Difficult: 2, Target: 1 << (maxTargetElement + 1)},
+	} {
+		assert.NotNil(t, opts.validate(), "%+v", opts)
+	}
+}
+
+func TestNew_target(t *testing.T) {
+	g, err := New(Options{Width: 3, Height: 3, Difficult: 1, Target: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, g.target)
+	g.board[0] = 2
+	assert.True(t, g.Won())
+}
+
+func TestNew_seed(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Seed = 42
+	moves := []Direction{Left, Up, Right, Down, Left, Left, Up, Right}
+	play := func() *Game {
+		g, err := New(opts)
+		assert.Nil(t, err)
+		for _, direction := range moves {
+			g.Move(direction)
+		}
+		return g
+	}
+	g, replay := play(), play()
+	assert.Equal(t, int64(42), g.Seed())
+	assert.Equal(t, g.Board(), replay.Board(), "same seed and moves, same game")
+	assert.Equal(t, g.Score(), replay.Score())
+
+	g.Restart()
+	first, _ := New(opts)
+	assert.Equal(t, first.Board(), g.Board(), "seeded game starts over from the same board")
+
+	// a random game can be replayed by its seed
+	random, _ := New(DefaultOptions())
+	assert.NotEqual(t, int64(0), random.Seed())
+	opts.Seed = random.Seed()
+	again, _ := New(opts)
+	assert.Equal(t, random.Board(), again.Board())
+}
+
+func TestDailySeed(t *testing.T) {
+	morning := time.Date(2026, 10, 19, 0, 30, 0, 0, time.UTC)
+	night := time.Date(2026, 10, 19, 23, 30, 0, 0, time.UTC)
+	assert.Equal(t, int64(20261019), DailySeed(morning))
+	assert.Equal(t, DailySeed(morning), DailySeed(night))
+	assert.Equal(t, DailySeed(night), DailySeed(night.In(time.FixedZone("UTC+8", 8*3600))), "days change in UTC")
+	assert.NotEqual(t, DailySeed(night), DailySeed(night.Add(time.Hour)))
+	assert.Equal(t, DailySeed(night), DailyOptions(night).Seed)
+}
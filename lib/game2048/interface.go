@@ -1,8 +1,6 @@
 package game2048
 
 import (
-	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/nsf/termbox-go"
@@ -11,84 +9,268 @@ import (
 const (
 	keyEsc = 0
 
-	keyUp    = 1
-	keyDown  = 2
-	keyLeft  = 4
-	keyRight = 8
+	keyUp        = 1
+	keyDown      = 2
+	keyLeft      = 4
+	keyRight     = 8
+	keyRestart   = 16
+	keyKeepGoing = 32
+	keyUndo      = 64
+
+	keySolverHint = 128
+	keyAutoplay   = 256
 )
 
-func process(g Game, inputCh chan int) {
-	for input := range inputCh {
-		switch input {
-		case keyUp:
-			g.operate(g.up)
-		case keyDown:
-			g.operate(g.down)
-		case keyLeft:
-			g.operate(g.left)
-		case keyRight:
-			g.operate(g.right)
-		case keyEsc:
-			return
-		}
+// autoplayInterval between moves of advisor
+const autoplayInterval = 100 * time.Millisecond
+
+// Advisor picks moves of a game, see package solver
+type Advisor interface {
+	Move(g *Game) (Direction, bool)
+}
+
+// advisor of hints and autoplay in games started by Run
+var advisor Advisor
+
+// UseAdvisor for the hint and autoplay keys from now on, nil turns them off
+func UseAdvisor(a Advisor) {
+	advisor = a
+}
+
+var moveDirections = map[int]Direction{
+	keyUp:    Up,
+	keyDown:  Down,
+	keyLeft:  Left,
+	keyRight: Right,
+}
+
+// screens shown over the board
+const (
+	screenPlaying = iota
+	screenWon     // target reached, player may keep going
+	screenOver    // no tile can move
+)
+
+// frame is an immutable snapshot of the game handed to the renderer,
+// it owns its board
+type frame struct {
+	name          string
+	board         []int
+	height, width int
+	score         int
+	screen        int
+	target        int
+	undos         int // used
+	seed          int64
+	advised       bool   // hint and autoplay keys work
+	hint          string // move suggested by advisor, empty if not asked
+	autoplay      bool
+	step, steps   int // of replay, steps is 0 while playing
+}
+
+func (g *Game) frame(name string, screen int) frame {
+	return frame{
+		name:   name,
+		board:  append([]int(nil), g.board...),
+		height: g.height,
+		width:  g.width,
+		score:  g.score,
+		screen: screen,
+		target: 1 << g.target,
+		undos:  g.undosUsed,
+		seed:   g.seed,
+	}
+}
+
+// screen of g, the win screen is shown until player keeps going
+func (g *Game) screen(keptGoing bool) int {
+	switch {
+	case g.Won() && !keptGoing:
+		return screenWon
+	case !g.Alive():
+		return screenOver
+	default:
+		return screenPlaying
 	}
 }
 
-func render(
-	g Game,
-	renderFunc func(board []int, height, width, score, fps int),
-	stopCh <-chan struct{}) {
+// process owns g, every change of it is handed to the renderer as a frame
+// and logged to logCh for replays. frameCh is closed once player quits, it
+// returns the record of every game played, restarted or quit.
+func process(g *Game, name string, inputCh chan int, logCh chan string, frameCh chan<- frame) (records []Record) {
+	defer close(frameCh)
+	keptGoing, hint := false, ""
+	// help of advisor in current game, kept in its record
+	hintsUsed, autoMoves := 0, 0
+	record := func() Record {
+		r := g.record(name)
+		r.HintsUsed, r.AutoMoves = hintsUsed, autoMoves
+		return r
+	}
+	// advisor moves on every tick while autoplay is on
+	var ticker *time.Ticker
+	var tickCh <-chan time.Time
+	stopAutoplay := func() {
+		if ticker != nil {
+			ticker.Stop()
+			ticker, tickCh = nil, nil
+		}
+	}
+	defer stopAutoplay()
+	newFrame := func() frame {
+		f := g.frame(name, g.screen(keptGoing))
+		f.advised, f.hint, f.autoplay = advisor != nil, hint, ticker != nil
+		return f
+	}
 
+	log(logCh, g.startEntry())
+	frameCh <- newFrame()
 	for {
+		input, autoMove := 0, false
 		select {
-		case <-stopCh:
-			return
+		case in, ok := <-inputCh:
+			if !ok {
+				return append(records, record())
+			}
+			input = in
+		case <-tickCh:
+			autoMove = true
+		}
+		screen := g.screen(keptGoing)
+		direction, isMove := moveDirections[input]
+		switch {
+		case autoMove:
+			if d, ok := advisor.Move(g); ok && screen == screenPlaying {
+				if moved, _ := g.Move(d); moved {
+					log(logCh, g.moveEntry(d))
+					autoMoves++
+				}
+			} else {
+				stopAutoplay()
+			}
+			hint = ""
+		case input == keyEsc:
+			return append(records, record())
+		case input == keyRestart && screen != screenPlaying:
+			records = append(records, record())
+			g.Restart()
+			log(logCh, g.startEntry())
+			keptGoing, hint = false, ""
+			hintsUsed, autoMoves = 0, 0
+		case input == keyKeepGoing && screen == screenWon:
+			keptGoing = true
+		case input == keyUndo:
+			if !g.Undo() {
+				continue
+			}
+			log(logCh, replayEntry{Event: eventUndo})
+			hint = ""
+		case input == keySolverHint && advisor != nil && screen == screenPlaying:
+			d, ok := advisor.Move(g)
+			if !ok {
+				continue
+			}
+			hint = d.String()
+			hintsUsed++
+		case input == keyAutoplay && advisor != nil && ticker != nil:
+			stopAutoplay()
+		case input == keyAutoplay && advisor != nil && screen == screenPlaying:
+			ticker = time.NewTicker(autoplayInterval)
+			tickCh = ticker.C
+		case isMove && screen == screenPlaying:
+			if moved, _ := g.Move(direction); moved {
+				log(logCh, g.moveEntry(direction))
+			}
+			hint = ""
 		default:
-			renderFunc(g.board, g.height, g.width, g.score, g.fps)
+			continue
+		}
+		if g.screen(keptGoing) != screenPlaying {
+			stopAutoplay()
 		}
+		frameCh <- newFrame()
 	}
 }
 
-func log(logCh chan string, direction int) {
-
+// render draws frames until frameCh is closed
+func render(frameCh <-chan frame, renderFunc func(f frame)) {
+	for f := range frameCh {
+		renderFunc(f)
+	}
 }
 
 func run(
-	g Game,
+	g *Game,
 	name string,
-	width, height, difficult int,
 	inputChannel chan int,
 	logChannel chan string,
-	renderFunc func(board []int, height, width, score, fps int),
-) (score int) {
-	g.init(4, 4, 2)
-
-	stopRenderCh := make(chan struct{})
-	go render(g, renderFunc, stopRenderCh)
-	defer close(stopRenderCh)
+	renderFunc func(f frame),
+) []Record {
+	frameCh := make(chan frame)
+	renderDoneCh := make(chan struct{})
+	go func() {
+		render(frameCh, renderFunc)
+		close(renderDoneCh)
+	}()
+	records := process(g, name, inputChannel, logChannel, frameCh)
+	<-renderDoneCh // last frame is drawn
+	return records
+}
 
-	process(g, inputChannel)
+func (g *Game) record(name string) Record {
+	return Record{
+		Name: name, Score: g.score, Won: g.Won(), UndosUsed: g.undosUsed,
+		Width: g.width, Height: g.height, Difficult: g.difficult, Target: 1 << g.target,
+		Seed: g.seed,
+	}
+}
 
-	return g.score
+// Record of a finished game, kept in score records under player name
+type Record struct {
+	Name      string `json:"name"`
+	Score     int    `json:"score"`
+	Won       bool   `json:"won"`
+	UndosUsed int    `json:"undosUsed"` // runs without undo, hint and autoplay are pure
+	HintsUsed int    `json:"hintsUsed"`
+	AutoMoves int    `json:"autoMoves"` // made by autoplay
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Difficult int    `json:"difficult"`
+	Target    int    `json:"target"`
+	Seed      int64  `json:"seed"`
 }
 
-// Run is the entrance of game 2048 in cmd
-func Run(name string, width int, height int, difficult int) {
-	rand.Seed(time.Now().UnixNano())
+// Run is the entrance of game 2048 in cmd, it returns the records of every
+// game played once player quits
+func Run(name string, opts Options) ([]Record, error) {
+	g, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
 	if err := termbox.Init(); err != nil {
-		panic(err)
+		return nil, err
 	}
 	defer termbox.Close()
 
-	// log.SetOutput(os.Stdout)
-	// log.SetLevel(log.InfoLevel)
-
 	inputChannel := make(chan int, 5)
 	logChannel := make(chan string, 5)
+	replayErrCh := make(chan error, 1)
+	go func() {
+		replayErrCh <- writeReplay(replayPath, logChannel)
+	}()
 
-	go listenToInput(inputChannel)
+	stopCh, listenDoneCh := make(chan struct{}), make(chan struct{})
+	go func() {
+		listenToInput(inputChannel, stopCh)
+		close(listenDoneCh)
+	}()
+	defer func() {
+		close(stopCh)
+		termbox.Interrupt()
+		<-listenDoneCh
+	}()
 
-	game := Game{}
-	score := run(game, name, width, height, difficult, inputChannel, logChannel, renderToScreen)
-	fmt.Println("your final socre is: ", score)
+	records := run(g, name, inputChannel, logChannel, renderToScreen)
+	close(logChannel)
+	return records, <-replayErrCh
 }
@@ -0,0 +1,145 @@
+package game2048
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	inputCh := make(chan int, 6)
+	for _, input := range []int{keyLeft, keyRight, -1, keyUp, keyDown, keyEsc} {
+		inputCh <- input
+	}
+	var frames []frame
+	g, err := New(Options{Width: 5, Height: 3, Difficult: 2})
+	assert.Nil(t, err)
+	records := run(g, "tester", inputCh, nil, func(f frame) {
+		frames = append(frames, f)
+	})
+
+	assert.Equal(t, []Record{{Name: "tester", Score: g.score, Width: 5, Height: 3, Difficult: 2, Target: 2048, Seed: g.Seed()}}, records)
+	assert.Len(t, frames, 5, "first frame and one per move")
+	last := frames[len(frames)-1]
+	assert.Equal(t, g.board, last.board)
+	assert.Equal(t, g.score, last.score)
+	assert.Equal(t, "tester", last.name)
+	assert.Equal(t, 5, last.width)
+	assert.Equal(t, 3, last.height)
+
+	// frames own their boards
+	last.board[0]++
+	assert.NotEqual(t, g.board[0], last.board[0])
+}
+
+// run g with inputs then quit, return records and frames rendered
+func runInputs(g *Game, inputs ...int) ([]Record, []frame) {
+	inputCh := make(chan int, len(inputs)+1)
+	for _, input := range append(inputs, keyEsc) {
+		inputCh <- input
+	}
+	var frames []frame
+	records := run(g, "tester", inputCh, nil, func(f frame) {
+		frames = append(frames, f)
+	})
+	return records, frames
+}
+
+func TestRun_won(t *testing.T) {
+	g := newTestGame(3, 3, []int{
+		2, 2, 0,
+		0, 0, 0,
+		0, 0, 0,
+	})
+	g.target = 3
+	records, frames := runInputs(g, keyLeft, keyLeft, keyKeepGoing, keyRestart)
+	if assert.Len(t, records, 1) {
+		assert.True(t, records[0].Won)
+		assert.Equal(t, 8, records[0].Target)
+	}
+	if assert.Len(t, frames, 3, "moves are ignored on win screen, restart while playing") {
+		assert.Equal(t, screenPlaying, frames[0].screen)
+		assert.Equal(t, screenWon, frames[1].screen)
+		assert.Equal(t, 8, frames[1].target)
+		assert.Equal(t, screenPlaying, frames[2].screen, "keep going")
+	}
+}
+
+func TestRun_over(t *testing.T) {
+	g := newTestGame(3, 3, []int{
+		3, 4, 3,
+		4, 3, 4,
+		4, 3, 0,
+	})
+	records, frames := runInputs(g, keyRight, keyLeft, keyKeepGoing, keyRestart)
+	if assert.Len(t, frames, 3) {
+		assert.Equal(t, screenOver, frames[1].screen)
+		assert.Equal(t, screenPlaying, frames[2].screen, "restarted")
+	}
+	if assert.Len(t, records, 2, "the finished game and the restarted one") {
+		assert.False(t, records[0].Won)
+		assert.NotEqual(t, records[0].Seed, records[1].Seed, "a new game")
+	}
+	assert.True(t, g.Alive())
+	assert.Equal(t, 7, g.boardFree, "a new board with two numbers")
+}
+
+// cycleAdvisor suggests its directions in turn
+type cycleAdvisor struct {
+	directions []Direction
+	calls      int
+}
+
+func (a *cycleAdvisor) Move(g *Game) (Direction, bool) {
+	d := a.directions[a.calls%len(a.directions)]
+	a.calls++
+	return d, g.Alive()
+}
+
+func TestRun_hint(t *testing.T) {
+	defer UseAdvisor(nil)
+	g := newTestGame(3, 3, []int{
+		1, 0, 0,
+		0, 0, 0,
+		0, 0, 0,
+	})
+	_, frames := runInputs(g, keySolverHint)
+	assert.Len(t, frames, 1, "no hint without advisor")
+	assert.False(t, frames[0].advised)
+
+	UseAdvisor(&cycleAdvisor{directions: []Direction{Right}})
+	records, frames := runInputs(g, keySolverHint, keyDown)
+	assert.Equal(t, 1, records[0].HintsUsed)
+	if assert.Len(t, frames, 3) {
+		assert.True(t, frames[0].advised)
+		assert.Equal(t, "right", frames[1].hint)
+		assert.Equal(t, "", frames[2].hint, "hint is gone after a move")
+	}
+}
+
+func TestRun_autoplay(t *testing.T) {
+	advisor := &cycleAdvisor{directions: []Direction{Left, Right}}
+	UseAdvisor(advisor)
+	defer UseAdvisor(nil)
+	g, err := New(Options{Width: 4, Height: 4, Difficult: 1})
+	assert.Nil(t, err)
+
+	inputCh := make(chan int)
+	go func() {
+		inputCh <- keyAutoplay
+		time.Sleep(5 * autoplayInterval)
+		inputCh <- keyAutoplay
+		inputCh <- keyEsc
+	}()
+	var frames []frame
+	records := run(g, "tester", inputCh, nil, func(f frame) {
+		frames = append(frames, f)
+	})
+	assert.GreaterOrEqual(t, advisor.calls, 2, "advisor plays")
+	assert.Greater(t, records[0].AutoMoves, 0, "autoplay moves are kept in record")
+	assert.LessOrEqual(t, records[0].AutoMoves, advisor.calls)
+	assert.Greater(t, len(frames), 3)
+	assert.True(t, frames[1].autoplay)
+	assert.False(t, frames[len(frames)-1].autoplay, "autoplay is toggled off")
+}
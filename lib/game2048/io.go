@@ -2,7 +2,7 @@ package game2048
 
 import (
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/mattn/go-runewidth"
 	"github.com/nsf/termbox-go"
@@ -49,49 +49,99 @@ var strMap = []string{
 }
 
 //  =================== Utils ===================
-func listenToInput(inputCh chan int) {
+
+// listenToInput push key input into channel until stopCh is closed
+// (call termbox.Interrupt to wake it up)
+func listenToInput(inputCh chan int, stopCh <-chan struct{}) {
 	termbox.SetInputMode(termbox.InputEsc)
 	for {
 		switch ev := termbox.PollEvent(); ev.Type {
 		case termbox.EventKey:
-			switch ev.Key {
-			case termbox.KeyArrowLeft:
-				inputCh <- keyLeft
-			case termbox.KeyArrowDown:
-				inputCh <- keyDown
-			case termbox.KeyArrowRight:
-				inputCh <- keyRight
-			case termbox.KeyArrowUp:
-				inputCh <- keyUp
-			case termbox.KeyEsc:
-				inputCh <- keyEsc
+			if action, ok := bindings.Action(ev); ok {
+				select {
+				case inputCh <- actionKeys[action]:
+				case <-stopCh: // dropped, wait for the interrupt
+				}
 			}
 
+		case termbox.EventInterrupt:
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
 		case termbox.EventError:
 			panic(ev.Err)
 		}
 	}
 }
 
-func renderToScreen(board []int, height, width, score, fps int) {
+// text and color of element, numbers too wide for a cell are powers
+func elementText(e int) (string, termbox.Attribute) {
+	color := termbox.ColorBlack
+	if e < len(colorMap) {
+		color = colorMap[e]
+	}
+	if e < len(strMap) {
+		return strMap[e], color
+	}
+	return fmt.Sprintf("2^%-3d", e), color
+}
+
+// every cell is 5 wide between borders, and 1 high between border lines
+func renderToScreen(f frame) {
+	board, height, width := f.board, f.height, f.width
 	if err := termbox.Clear(termbox.ColorDefault, termbox.ColorDefault); err != nil {
 		panic(err)
 	}
-	tbprint(30, 0, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("score:%d", score))
+	border := strings.Repeat("-", 6*width+1)
+	panelX := len(border) + 5
+	tbprint(panelX, 0, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("score:%d", f.score))
+	tbprint(panelX, 1, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("player:%s", f.name))
+	tbprint(panelX, 2, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("undos:%d", f.undos))
+	tbprint(panelX, 3, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("[%s] undo", keyHint("undo")))
+	tbprint(panelX, 4, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("seed:%d", f.seed))
+	if f.advised {
+		tbprint(panelX, 5, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("[%s] hint  [%s] autoplay",
+			keyHint("hint"), keyHint("autoplay")))
+	}
+	if f.hint != "" {
+		tbprint(panelX, 6, termbox.ColorYellow, termbox.ColorDefault, fmt.Sprintf("hint:%s", f.hint))
+	}
+	if f.autoplay {
+		tbprint(panelX, 7, termbox.ColorGreen, termbox.ColorDefault, "autoplay")
+	}
+	if f.steps > 0 {
+		tbprint(panelX, 5, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("step:%d/%d", f.step, f.steps))
+		tbprint(panelX, 6, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("[%s] back  [%s] forward",
+			keyHint("left"), keyHint("right")))
+		tbprint(panelX, 7, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("[%s] first  [%s] last  [%s] quit",
+			keyHint("up"), keyHint("down"), keyHint("quit")))
+	}
 
-	tbprint(0, 0, termbox.ColorDefault, termbox.ColorDefault, "-------------------------")
+	tbprint(0, 0, termbox.ColorDefault, termbox.ColorDefault, border)
 	for i := 0; i < height; i++ {
 		tbprint(0, i*2+1, termbox.ColorDefault, termbox.ColorDefault, "|")
 		for j := 0; j < width; j++ {
-			tbprint(6*j+1, i*2+1, colorMap[board[i*width+j]], termbox.ColorBlack, strMap[board[i*width+j]])
+			str, color := elementText(board[i*width+j])
+			tbprint(6*j+1, i*2+1, color, termbox.ColorBlack, str)
 			tbprint(6*j+6, i*2+1, termbox.ColorDefault, termbox.ColorDefault, "|")
 		}
-		tbprint(0, i*2+2, termbox.ColorDefault, termbox.ColorDefault, "-------------------------")
+		tbprint(0, i*2+2, termbox.ColorDefault, termbox.ColorDefault, border)
+	}
+	switch y := height*2 + 2; f.screen {
+	case screenWon:
+		tbprint(0, y, termbox.ColorGreen, termbox.ColorDefault, fmt.Sprintf("You reached %d!", f.target))
+		tbprint(0, y+1, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("[%s] keep going  [%s] restart  [%s] quit",
+			keyHint("keepGoing"), keyHint("restart"), keyHint("quit")))
+	case screenOver:
+		tbprint(0, y, termbox.ColorRed, termbox.ColorDefault, "Game over, no more moves")
+		tbprint(0, y+1, termbox.ColorDefault, termbox.ColorDefault, fmt.Sprintf("[%s] restart  [%s] quit",
+			keyHint("restart"), keyHint("quit")))
 	}
 	if err := termbox.Flush(); err != nil {
 		panic(err)
 	}
-	time.Sleep(time.Duration(1000/fps) * time.Millisecond)
 }
 
 // This function is often useful:
@@ -0,0 +1,77 @@
+package game2048
+
+import (
+	"strings"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/keymap"
+)
+
+// keymap section of 2048 in config file
+const keymapSection = "2048"
+
+// input of each keymap action
+var actionKeys = map[string]int{
+	"up":        keyUp,
+	"down":      keyDown,
+	"left":      keyLeft,
+	"right":     keyRight,
+	"quit":      keyEsc,
+	"restart":   keyRestart,
+	"keepGoing": keyKeepGoing,
+	"undo":      keyUndo,
+	"hint":      keySolverHint,
+	"autoplay":  keyAutoplay,
+}
+
+var defaultKeymap = keymap.MustNew(map[string][]string{
+	"up":        {"Up"},
+	"down":      {"Down"},
+	"left":      {"Left"},
+	"right":     {"Right"},
+	"quit":      {"Esc"},
+	"restart":   {"r"},
+	"keepGoing": {"Enter"},
+	"undo":      {"u"},
+	"hint":      {"?"},
+	"autoplay":  {"p"},
+})
+
+var keymapPresets = map[string]*keymap.Keymap{
+	keymap.DefaultPreset: defaultKeymap,
+	"wasd": defaultKeymap.With(map[string][]string{
+		"up":    {"w", "Up"},
+		"down":  {"s", "Down"},
+		"left":  {"a", "Left"},
+		"right": {"d", "Right"},
+	}),
+	"vim": defaultKeymap.With(map[string][]string{
+		"up":    {"k", "Up"},
+		"down":  {"j", "Down"},
+		"left":  {"h", "Left"},
+		"right": {"l", "Right"},
+		"quit":  {"q", "Esc"},
+	}),
+}
+
+// key bindings used by listenToInput
+var bindings = defaultKeymap
+
+// first key of action, as shown in screen hints
+func keyHint(action string) string {
+	keys := bindings.Keys(action)
+	if len(keys) == 0 {
+		return "-"
+	}
+	return strings.ToLower(keys[0].String())
+}
+
+// LoadKeymap load key bindings of 2048 from config file, a missing file
+// keeps the default keys
+func LoadKeymap(path string) error {
+	km, err := keymap.LoadFile(path, keymapSection, keymapPresets)
+	if err != nil {
+		return err
+	}
+	bindings = km
+	return nil
+}
@@ -0,0 +1,49 @@
+package game2048
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// AppendRecord add record to the score records in path, one JSON record
+// per line
+func AppendRecord(path string, record Record) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(record); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// BestRecord of player name in score records, false if there is none
+func BestRecord(path, name string) (Record, bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Record{}, false, nil
+	}
+	if err != nil {
+		return Record{}, false, err
+	}
+	defer f.Close()
+	best, found := Record{}, false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return Record{}, false, err
+		}
+		if record.Name == name && (!found || record.Score > best.Score) {
+			best, found = record, true
+		}
+	}
+	return best, found, scanner.Err()
+}
@@ -0,0 +1,299 @@
+package game2048
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nsf/termbox-go"
+)
+
+// events of replay log
+const (
+	eventStart = "start" // a new board, after Run or restart
+	eventMove  = "move"
+	eventUndo  = "undo"
+)
+
+// replayEntry is a line of replay log, in JSON
+type replayEntry struct {
+	Event     string        `json:"event"`
+	Width     int           `json:"width,omitempty"`  // start only
+	Height    int           `json:"height,omitempty"` // start only
+	Seed      int64         `json:"seed,omitempty"`   // start only
+	Direction string        `json:"direction,omitempty"`
+	Spawns    []replaySpawn `json:"spawns,omitempty"`
+}
+
+// replaySpawn is a new number at pos (row*width+column) of board
+type replaySpawn struct {
+	Pos   int `json:"pos"`
+	Value int `json:"value"`
+}
+
+func (g *Game) spawns() []replaySpawn {
+	var spawns []replaySpawn
+	for _, pos := range g.spawned {
+		spawns = append(spawns, replaySpawn{Pos: pos, Value: 1 << g.board[pos]})
+	}
+	return spawns
+}
+
+// startEntry of a new board
+func (g *Game) startEntry() replayEntry {
+	return replayEntry{Event: eventStart, Width: g.width, Height: g.height, Seed: g.seed, Spawns: g.spawns()}
+}
+
+// moveEntry of a valid move to direction
+func (g *Game) moveEntry(direction Direction) replayEntry {
+	return replayEntry{Event: eventMove, Direction: direction.String(), Spawns: g.spawns()}
+}
+
+// log entry to logCh as a line of replay log, a nil logCh drops it
+func log(logCh chan string, entry replayEntry) {
+	if logCh == nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		panic(err)
+	}
+	logCh <- string(line)
+}
+
+// replays of games started by Run, empty for none
+var replayPath string
+
+// SaveReplays of games started by Run to path from now on, each Run
+// replaces the replay log of the last one
+func SaveReplays(path string) {
+	replayPath = path
+}
+
+// writeReplay lines from logCh to path until logCh is closed, lines are
+// dropped if path is empty
+func writeReplay(path string, logCh <-chan string) (err error) {
+	var w *bufio.Writer
+	if path != "" {
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			var f *os.File
+			if f, err = os.Create(path); err == nil {
+				defer func() {
+					if closeErr := f.Close(); err == nil {
+						err = closeErr
+					}
+				}()
+				w = bufio.NewWriter(f)
+			}
+		}
+	}
+	for line := range logCh {
+		if w != nil && err == nil {
+			_, err = fmt.Fprintln(w, line)
+		}
+	}
+	if w != nil && err == nil {
+		err = w.Flush()
+	}
+	return err
+}
+
+// replayStep is the game after an entry of replay log
+type replayStep struct {
+	board         []int
+	width, height int
+	score         int
+	seed          int64
+}
+
+// Replay of games in a replay log, step by step
+type Replay struct {
+	steps []replayStep
+}
+
+// LoadReplay from replay log in path
+func LoadReplay(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readReplay(f)
+}
+
+func parseDirection(name string) (Direction, bool) {
+	for d, n := range directionNames {
+		if n == name {
+			return Direction(d), true
+		}
+	}
+	return 0, false
+}
+
+// readReplay replays every entry on a board of its own, an undo steps back
+// to the board before last move
+func readReplay(r io.Reader) (*Replay, error) {
+	replay := &Replay{}
+	var g *Game
+	var undos []replayStep
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		var entry replayEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("replay line %d: %v", line, err)
+		}
+		if g == nil && entry.Event != eventStart {
+			return nil, fmt.Errorf("replay line %d: %s before start", line, entry.Event)
+		}
+		switch entry.Event {
+		case eventStart:
+			w, h := entry.Width, entry.Height
+			if w < minSide || w > maxSide || h < minSide || h > maxSide {
+				return nil, fmt.Errorf("replay line %d: board %dx%d is out of %dx%d to %dx%d", line, w, h, minSide, minSide, maxSide, maxSide)
+			}
+			g = &Game{width: w, height: h, up: -w, down: w, left: -1, right: 1, seed: entry.Seed}
+			g.board, g.boardFree = make([]int, w*h), w*h
+			undos = nil
+		case eventMove:
+			direction, ok := parseDirection(entry.Direction)
+			if !ok {
+				return nil, fmt.Errorf("replay line %d: unknown direction %q", line, entry.Direction)
+			}
+			undos = append(undos, replay.steps[len(replay.steps)-1])
+			g.lastMoveValid = false
+			g.slide(g.offset(direction))
+		case eventUndo:
+			if len(undos) == 0 {
+				return nil, fmt.Errorf("replay line %d: nothing to undo", line)
+			}
+			last := undos[len(undos)-1]
+			undos = undos[:len(undos)-1]
+			copy(g.board, last.board)
+			g.score, g.boardFree = last.score, 0
+			for _, e := range g.board {
+				if e == emptyElement {
+					g.boardFree++
+				}
+			}
+		default:
+			return nil, fmt.Errorf("replay line %d: unknown event %q", line, entry.Event)
+		}
+		for _, spawn := range entry.Spawns {
+			e := element(spawn.Value)
+			if spawn.Pos < 0 || spawn.Pos >= len(g.board) || g.board[spawn.Pos] != emptyElement ||
+				e < 1 || spawn.Value != 1<<e {
+				return nil, fmt.Errorf("replay line %d: can not spawn %d at %d", line, spawn.Value, spawn.Pos)
+			}
+			g.board[spawn.Pos] = e
+			g.boardFree--
+		}
+		replay.steps = append(replay.steps, replayStep{
+			board: append([]int(nil), g.board...), width: g.width, height: g.height,
+			score: g.score, seed: g.seed,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(replay.steps) == 0 {
+		return nil, fmt.Errorf("replay is empty")
+	}
+	return replay, nil
+}
+
+// Steps of replay, one per entry of replay log
+func (r *Replay) Steps() int {
+	return len(r.steps)
+}
+
+// Board after step i, tile values by row from the top, 0 for empty
+func (r *Replay) Board(i int) [][]int {
+	step := r.steps[i]
+	board := make([][]int, step.height)
+	for row := range board {
+		board[row] = make([]int, step.width)
+		for col := range board[row] {
+			if e := step.board[row*step.width+col]; e != emptyElement {
+				board[row][col] = 1 << e
+			}
+		}
+	}
+	return board
+}
+
+// Score after step i
+func (r *Replay) Score(i int) int {
+	return r.steps[i].score
+}
+
+func (r *Replay) frame(i int) frame {
+	step := r.steps[i]
+	return frame{
+		name:   "replay",
+		board:  append([]int(nil), step.board...),
+		height: step.height,
+		width:  step.width,
+		score:  step.score,
+		screen: screenPlaying,
+		seed:   step.seed,
+		step:   i + 1,
+		steps:  len(r.steps),
+	}
+}
+
+// viewReplay steps forward (right) and backward (left), up and down go to
+// the first and last step, until player quits
+func viewReplay(r *Replay, inputCh chan int, renderFunc func(f frame)) {
+	i := 0
+	renderFunc(r.frame(i))
+	for input := range inputCh {
+		next := i
+		switch input {
+		case keyEsc:
+			return
+		case keyLeft:
+			next--
+		case keyRight:
+			next++
+		case keyUp:
+			next = 0
+		case keyDown:
+			next = len(r.steps) - 1
+		}
+		if next < 0 || next >= len(r.steps) || next == i {
+			continue
+		}
+		i = next
+		renderFunc(r.frame(i))
+	}
+}
+
+// RunReplay view replay log in path
+func RunReplay(path string) error {
+	r, err := LoadReplay(path)
+	if err != nil {
+		return err
+	}
+	if err := termbox.Init(); err != nil {
+		return err
+	}
+	defer termbox.Close()
+
+	inputChannel := make(chan int, 5)
+	stopCh, listenDoneCh := make(chan struct{}), make(chan struct{})
+	go func() {
+		listenToInput(inputChannel, stopCh)
+		close(listenDoneCh)
+	}()
+	defer func() {
+		close(stopCh)
+		termbox.Interrupt()
+		<-listenDoneCh
+	}()
+
+	viewReplay(r, inputChannel, renderToScreen)
+	return nil
+}
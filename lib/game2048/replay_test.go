@@ -0,0 +1,113 @@
+package game2048
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// play a seeded 3x3 game with inputs then quit, return replay log lines
+func playLogged(t *testing.T, inputs ...int) (*Game, []string) {
+	g, err := New(Options{Width: 3, Height: 3, Difficult: 2, Undos: -1, Seed: 42})
+	assert.Nil(t, err)
+	inputCh := make(chan int, len(inputs)+1)
+	for _, input := range append(inputs, keyEsc) {
+		inputCh <- input
+	}
+	logCh := make(chan string, len(inputs)+1)
+	run(g, "tester", inputCh, logCh, func(f frame) {})
+	close(logCh)
+	var lines []string
+	for line := range logCh {
+		lines = append(lines, line)
+	}
+	return g, lines
+}
+
+func TestReplay(t *testing.T) {
+	g, lines := playLogged(t, keyLeft, keyUp, keyRight, keyUndo, keyDown, keyLeft)
+	assert.Contains(t, lines[0], `"event":"start"`)
+	assert.Contains(t, lines[0], `"seed":42`)
+	assert.Equal(t, `{"event":"undo"}`, lines[4])
+
+	r, err := readReplay(strings.NewReader(strings.Join(lines, "\n")))
+	assert.Nil(t, err)
+	assert.Equal(t, len(lines), r.Steps())
+	last := r.Steps() - 1
+	assert.Equal(t, g.Board(), r.Board(last))
+	assert.Equal(t, g.Score(), r.Score(last))
+	assert.Equal(t, r.Board(2), r.Board(4), "undo steps back")
+}
+
+func TestReplay_restart(t *testing.T) {
+	var inputs []int
+	for i := 0; i < 200; i++ {
+		inputs = append(inputs, keyLeft, keyDown, keyRight, keyUp)
+	}
+	g, lines := playLogged(t, append(inputs, keyRestart, keyLeft, keyUp)...)
+	assert.Equal(t, 2, strings.Count(strings.Join(lines, "\n"), `"event":"start"`), "game over then restart")
+
+	r, err := readReplay(strings.NewReader(strings.Join(lines, "\n")))
+	assert.Nil(t, err)
+	assert.Equal(t, g.Board(), r.Board(r.Steps()-1))
+	assert.Equal(t, g.Score(), r.Score(r.Steps()-1))
+}
+
+func TestWriteReplay(t *testing.T) {
+	_, lines := playLogged(t, keyLeft, keyUp)
+	path := filepath.Join(t.TempDir(), "replays", "2048.jsonl")
+	logCh := make(chan string, len(lines))
+	for _, line := range lines {
+		logCh <- line
+	}
+	close(logCh)
+	assert.Nil(t, writeReplay(path, logCh))
+	r, err := LoadReplay(path)
+	assert.Nil(t, err)
+	assert.Equal(t, len(lines), r.Steps())
+
+	// lines are drained without a path
+	logCh = make(chan string, 1)
+	logCh <- lines[0]
+	close(logCh)
+	assert.Nil(t, writeReplay("", logCh))
+}
+
+func TestReadReplay_invalid(t *testing.T) {
+	start := `{"event":"start","width":3,"height":3,"spawns":[{"pos":0,"value":2}]}`
+	for _, content := range []string{
+		"",
+		"not json",
+		`{"event":"move","direction":"left"}`,
+		`{"event":"start","width":2,"height":3}`,
+		start + "\n" + `{"event":"move","direction":"sideways"}`,
+		start + "\n" + `{"event":"undo"}`,
+		start + "\n" + `{"event":"jump"}`,
+		start + "\n" + `{"event":"move","direction":"left","spawns":[{"pos":0,"value":2}]}`,
+		start + "\n" + `{"event":"move","direction":"left","spawns":[{"pos":9,"value":2}]}`,
+		start + "\n" + `{"event":"move","direction":"right","spawns":[{"pos":0,"value":3}]}`,
+	} {
+		_, err := readReplay(strings.NewReader(content))
+		assert.NotNil(t, err, content)
+	}
+}
+
+func TestViewReplay(t *testing.T) {
+	_, lines := playLogged(t, keyLeft, keyUp, keyRight)
+	r, err := readReplay(strings.NewReader(strings.Join(lines, "\n")))
+	assert.Nil(t, err)
+
+	inputs := []int{keyLeft, keyRight, keyRight, keyDown, keyRight, keyLeft, keyUp, keyEsc}
+	inputCh := make(chan int, len(inputs))
+	for _, input := range inputs {
+		inputCh <- input
+	}
+	var steps []int
+	viewReplay(r, inputCh, func(f frame) {
+		assert.Equal(t, r.Steps(), f.steps)
+		steps = append(steps, f.step)
+	})
+	assert.Equal(t, []int{1, 2, 3, 4, 3, 1}, steps, "steps stop at both ends")
+}
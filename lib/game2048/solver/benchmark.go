@@ -0,0 +1,62 @@
+package solver
+
+import (
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/game2048"
+)
+
+// BenchmarkResult of games played by a solver
+type BenchmarkResult struct {
+	Games, Wins  int
+	WinRate      float64
+	AverageScore float64
+	BestTile     int
+}
+
+// Benchmark s on n games of opts until no tile can move, game i is seeded
+// by opts.Seed+i (opts.Seed 0 starts from 1) so results are repeatable
+func Benchmark(s *Solver, opts game2048.Options, n int) (BenchmarkResult, error) {
+	result := BenchmarkResult{Games: n}
+	if opts.Seed == 0 {
+		opts.Seed = 1
+	}
+	total := 0
+	for i := 0; i < n; i++ {
+		gameOpts := opts
+		gameOpts.Seed = opts.Seed + int64(i)
+		g, err := game2048.New(gameOpts)
+		if err != nil {
+			return BenchmarkResult{}, err
+		}
+		Play(s, g)
+		if g.Won() {
+			result.Wins++
+		}
+		total += g.Score()
+		for _, row := range g.Board() {
+			for _, tile := range row {
+				if tile > result.BestTile {
+					result.BestTile = tile
+				}
+			}
+		}
+	}
+	if n > 0 {
+		result.WinRate = float64(result.Wins) / float64(n)
+		result.AverageScore = float64(total) / float64(n)
+	}
+	return result, nil
+}
+
+// Play g by s until no tile can move, it returns the number of moves
+func Play(s *Solver, g *game2048.Game) int {
+	moves := 0
+	for g.Alive() {
+		direction, ok := s.Move(g)
+		if !ok {
+			break
+		}
+		g.Move(direction)
+		moves++
+	}
+	return moves
+}
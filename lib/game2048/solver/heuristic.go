@@ -0,0 +1,91 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/game2048"
+)
+
+const side = game2048.BitboardSide
+
+// weights of heuristic, a line scores better with more empty cells and
+// merges, tiles sorted in one direction and small steps between neighbours
+const (
+	baseScore          = 200000.0
+	emptyWeight        = 270.0
+	mergeWeight        = 700.0
+	monotonicityPower  = 4.0
+	monotonicityWeight = 47.0
+	smoothnessWeight   = 10.0
+	sumPower           = 3.5
+	sumWeight          = 11.0
+)
+
+// rowHeuris of every bitboard row, indexed by its 16 bits
+var rowHeuris [1 << 16]float64
+
+func init() {
+	for row := range rowHeuris {
+		var line [side]int
+		for c := range line {
+			line[c] = row >> (4 * c) & game2048.MaxBitboardElement
+		}
+		rowHeuris[row] = lineHeuristic(line)
+	}
+}
+
+// heuristic of rows and columns
+func heuristic(b game2048.Bitboard) float64 {
+	t := b.Transpose()
+	score := 0.0
+	for i := 0; i < side; i++ {
+		score += rowHeuris[b.Row(i)] + rowHeuris[t.Row(i)]
+	}
+	return score
+}
+
+// lineHeuristic of a row or column of elements
+func lineHeuristic(line [side]int) float64 {
+	empty, merges, sum := 0, 0, 0.0
+	prev, run := 0, 0
+	for _, e := range line {
+		sum += math.Pow(float64(e), sumPower)
+		if e == 0 {
+			empty++
+			continue
+		}
+		if e == prev {
+			run++
+		} else {
+			if run > 0 {
+				merges += 1 + run
+			}
+			prev, run = e, 0
+		}
+	}
+	if run > 0 {
+		merges += 1 + run
+	}
+
+	// monotonicity: penalty of the cheaper direction to sort the line in
+	monoLeft, monoRight := 0.0, 0.0
+	smoothness := 0.0
+	for i := 1; i < side; i++ {
+		a, b := math.Pow(float64(line[i-1]), monotonicityPower), math.Pow(float64(line[i]), monotonicityPower)
+		if line[i-1] > line[i] {
+			monoLeft += a - b
+		} else {
+			monoRight += b - a
+		}
+		if line[i-1] != 0 && line[i] != 0 {
+			smoothness += math.Abs(float64(line[i-1] - line[i]))
+		}
+	}
+
+	return baseScore +
+		emptyWeight*float64(empty) +
+		mergeWeight*float64(merges) -
+		monotonicityWeight*math.Min(monoLeft, monoRight) -
+		smoothnessWeight*smoothness -
+		sumWeight*sum
+}
@@ -0,0 +1,140 @@
+// Package solver plays 2048 by depth-limited expectimax over game2048.Bitboard,
+// only the classic 4x4 board is supported
+package solver
+
+import "github.com/SpicyChickenFLY/tiny-games-go/lib/game2048"
+
+// DefaultDepth of search in moves
+const DefaultDepth = 3
+
+// chance branches less likely than minProbability are not searched deeper
+const minProbability = 0.0001
+
+var directions = []game2048.Direction{game2048.Up, game2048.Down, game2048.Left, game2048.Right}
+
+// Solver picks moves of a game
+type Solver struct {
+	depth int
+}
+
+// New solver searching depth moves ahead, 0 means DefaultDepth
+func New(depth int) *Solver {
+	if depth <= 0 {
+		depth = DefaultDepth
+	}
+	return &Solver{depth: depth}
+}
+
+// spawn is a new element and its chance
+type spawn struct {
+	element     int
+	probability float64
+}
+
+// search of one move, boards seen at the same depth are cached
+type search struct {
+	spawns []spawn
+	cache  map[cacheKey]float64
+	pruned int // chance nodes cut short by minProbability
+}
+
+type cacheKey struct {
+	board game2048.Bitboard
+	depth int
+}
+
+func newSearch(policy game2048.SpawnPolicy) *search {
+	return &search{spawns: spawns(policy), cache: make(map[cacheKey]float64)}
+}
+
+// Move best for g, false if g has no Bitboard or no tile can move.
+// Adversarial spawns are taken as random ones.
+func (s *Solver) Move(g *game2048.Game) (game2048.Direction, bool) {
+	b, ok := g.Bitboard()
+	if !ok {
+		return 0, false
+	}
+	return s.best(newSearch(g.SpawnPolicy()), b)
+}
+
+// best move of b in search sr
+func (s *Solver) best(sr *search, b game2048.Bitboard) (game2048.Direction, bool) {
+	best, bestScore, found := game2048.Up, 0.0, false
+	for _, direction := range directions {
+		moved, _ := b.Move(direction)
+		if moved == b {
+			continue
+		}
+		score := sr.chance(moved, s.depth-1, 1)
+		if !found || score > bestScore {
+			best, bestScore, found = direction, score, true
+		}
+	}
+	return best, found
+}
+
+func spawns(policy game2048.SpawnPolicy) []spawn {
+	total := 0
+	for _, w := range policy.Weights {
+		total += w
+	}
+	var result []spawn
+	for i, w := range policy.Weights {
+		if w > 0 && i+1 <= game2048.MaxBitboardElement {
+			result = append(result, spawn{element: i + 1, probability: float64(w) / float64(total)})
+		}
+	}
+	return result
+}
+
+// chance node: the average over new numbers in every empty cell
+func (sr *search) chance(b game2048.Bitboard, depth int, probability float64) float64 {
+	if depth <= 0 {
+		return heuristic(b)
+	}
+	if probability < minProbability {
+		sr.pruned++
+		return heuristic(b)
+	}
+	key := cacheKey{board: b, depth: depth}
+	if score, ok := sr.cache[key]; ok {
+		return score
+	}
+	empty := b.Empty()
+	if empty == 0 {
+		return sr.max(b, depth, probability)
+	}
+	pruned := sr.pruned
+	probability /= float64(empty)
+	score := 0.0
+	for i := 0; i < side*side; i++ {
+		if b.Element(i/side, i%side) != 0 {
+			continue
+		}
+		for _, sp := range sr.spawns {
+			next := b.Set(i/side, i%side, sp.element)
+			score += sp.probability * sr.max(next, depth, probability*sp.probability)
+		}
+	}
+	score /= float64(empty)
+	// a pruned score depends on the chance the board is reached by
+	if sr.pruned == pruned {
+		sr.cache[key] = score
+	}
+	return score
+}
+
+// max node: the best move, 0 once no tile can move
+func (sr *search) max(b game2048.Bitboard, depth int, probability float64) float64 {
+	best := 0.0
+	for _, direction := range directions {
+		moved, _ := b.Move(direction)
+		if moved == b {
+			continue
+		}
+		if score := sr.chance(moved, depth-1, probability); score > best {
+			best = score
+		}
+	}
+	return best
+}
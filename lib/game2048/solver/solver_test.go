@@ -0,0 +1,74 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/game2048"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineHeuristic(t *testing.T) {
+	sorted := lineHeuristic([side]int{4, 3, 2, 1})
+	assert.Greater(t, sorted, lineHeuristic([side]int{3, 4, 1, 2}), "monotonicity")
+	assert.Greater(t, lineHeuristic([side]int{3, 3, 0, 0}), lineHeuristic([side]int{3, 0, 0, 4}), "merges")
+	assert.Greater(t, lineHeuristic([side]int{4, 0, 0, 0}), lineHeuristic([side]int{4, 1, 0, 0}), "empty cells")
+}
+
+func TestSolver_Move(t *testing.T) {
+	opts := game2048.DefaultOptions()
+	opts.Width, opts.Height = 3, 3
+	g, err := game2048.New(opts)
+	assert.Nil(t, err)
+	_, ok := New(0).Move(g)
+	assert.False(t, ok, "only 4x4 boards")
+
+	g, _ = game2048.New(game2048.DefaultOptions())
+	direction, ok := New(0).Move(g)
+	assert.True(t, ok)
+	moved, _ := g.Move(direction)
+	assert.True(t, moved, "the best move is a valid one")
+}
+
+// boards first met on unlikely branches do not change the move
+func TestSolver_MoveWarmCache(t *testing.T) {
+	opts := game2048.DefaultOptions()
+	opts.Seed = 1
+	g, err := game2048.New(opts)
+	assert.Nil(t, err)
+	b, _ := g.Bitboard()
+	s := New(0)
+	cold, warm := newSearch(g.SpawnPolicy()), newSearch(g.SpawnPolicy())
+	for _, direction := range directions {
+		moved, _ := b.Move(direction)
+		if moved != b {
+			warm.chance(moved, s.depth-1, 2*minProbability)
+		}
+	}
+	assert.Greater(t, warm.pruned, 0, "unlikely branches are pruned")
+	for _, direction := range directions {
+		moved, _ := b.Move(direction)
+		if moved != b {
+			assert.Equal(t, cold.chance(moved, s.depth-1, 1), warm.chance(moved, s.depth-1, 1), "%v", direction)
+		}
+	}
+	want, _ := s.best(newSearch(g.SpawnPolicy()), b)
+	got, _ := s.best(warm, b)
+	assert.Equal(t, want, got)
+}
+
+func TestBenchmark(t *testing.T) {
+	opts := game2048.DefaultOptions()
+	opts.Target = 256
+	result, err := Benchmark(New(1), opts, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Games)
+	assert.Equal(t, float64(result.Wins)/2, result.WinRate)
+	assert.Greater(t, result.AverageScore, 0.0)
+	assert.GreaterOrEqual(t, result.BestTile, 64)
+
+	again, _ := Benchmark(New(1), opts, 2)
+	assert.Equal(t, result, again, "seeded games repeat")
+
+	_, err = Benchmark(New(1), game2048.Options{}, 1)
+	assert.NotNil(t, err)
+}
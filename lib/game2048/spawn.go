@@ -0,0 +1,144 @@
+package game2048
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SpawnPolicy decides the new numbers on board
+type SpawnPolicy struct {
+	// Weights[i] is the relative chance of a new 2^(i+1)
+	Weights []int
+	// numbers on a new board, and after each valid move
+	Initial, PerMove int
+	// new numbers go to the cell leaving player the fewest free cells
+	// after their best move, instead of a random one
+	Adversarial bool
+}
+
+var spawnPresets = map[string]SpawnPolicy{
+	"classic":     {Weights: []int{9, 1}, Initial: 2, PerMove: 1},
+	"hard":        {Weights: []int{3, 1}, Initial: 2, PerMove: 2},
+	"adversarial": {Weights: []int{9, 1}, Initial: 2, PerMove: 1, Adversarial: true},
+}
+
+// SpawnPreset by name, see SpawnNames
+func SpawnPreset(name string) (SpawnPolicy, error) {
+	policy, ok := spawnPresets[name]
+	if !ok {
+		return SpawnPolicy{}, fmt.Errorf("unknown spawn policy %q, want one of %v", name, SpawnNames())
+	}
+	policy.Weights = append([]int(nil), policy.Weights...)
+	return policy, nil
+}
+
+// SpawnNames of presets, sorted
+func SpawnNames() []string {
+	names := make([]string, 0, len(spawnPresets))
+	for name := range spawnPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// uniformSpawn is 2^1 up to 2^difficult with equal chance
+func uniformSpawn(difficult int) SpawnPolicy {
+	weights := make([]int, difficult)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return SpawnPolicy{Weights: weights, Initial: 2, PerMove: 1}
+}
+
+func (p SpawnPolicy) validate(cells int) error {
+	if len(p.Weights) < 1 || len(p.Weights) > maxDifficult {
+		return fmt.Errorf("spawn weights %v should have 1 to %d numbers", p.Weights, maxDifficult)
+	}
+	total := 0
+	for _, w := range p.Weights {
+		if w < 0 {
+			return fmt.Errorf("spawn weights %v should not be negative", p.Weights)
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("spawn weights %v are all 0", p.Weights)
+	}
+	if p.Initial < 1 || p.Initial > cells || p.PerMove < 1 || p.PerMove > cells {
+		return fmt.Errorf("spawn %d initial and %d per move numbers are out of [1, %d]", p.Initial, p.PerMove, cells)
+	}
+	return nil
+}
+
+// spawn up to n new numbers in free cells
+func (g *Game) spawn(n int) {
+	for ; n > 0 && g.boardFree > 0; n-- {
+		e, pos := g.spawnElement(), 0
+		if g.spawnPolicy.Adversarial {
+			pos = g.worstCell(e)
+		} else {
+			pos = g.freeCell(g.rng.Intn(g.boardFree))
+		}
+		g.board[pos] = e
+		g.boardFree--
+		g.lastNewNumberIndex = pos
+		g.spawned = append(g.spawned, pos)
+	}
+}
+
+// element of a new number, drawn by weights
+func (g *Game) spawnElement() int {
+	total := 0
+	for _, w := range g.spawnPolicy.Weights {
+		total += w
+	}
+	n := g.rng.Intn(total)
+	for i, w := range g.spawnPolicy.Weights {
+		if n < w {
+			return i + 1
+		}
+		n -= w
+	}
+	return len(g.spawnPolicy.Weights)
+}
+
+// position of the k-th free cell
+func (g *Game) freeCell(k int) int {
+	for pos, e := range g.board {
+		if e == emptyElement {
+			if k == 0 {
+				return pos
+			}
+			k--
+		}
+	}
+	return -1
+}
+
+// worstCell for element e, where the best move of player leaves the
+// fewest free cells, a cell without any move is the worst
+func (g *Game) worstCell(e int) int {
+	worstPos, worst := -1, 0
+	probe := &Game{width: g.width, height: g.height, up: g.up, down: g.down, left: g.left, right: g.right}
+	for pos := range g.board {
+		if g.board[pos] != emptyElement {
+			continue
+		}
+		best := -1
+		for _, direction := range []int{g.up, g.down, g.left, g.right} {
+			probe.board = append(probe.board[:0], g.board...)
+			probe.board[pos] = e
+			probe.boardFree = g.boardFree - 1
+			probe.lastMoveValid = false
+			probe.slide(direction)
+			if probe.lastMoveValid && probe.boardFree > best {
+				best = probe.boardFree
+			}
+		}
+		if worstPos < 0 || best < worst {
+			worstPos, worst = pos, best
+		}
+	}
+	return worstPos
+}
@@ -0,0 +1,103 @@
+package game2048
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpawnPresets(t *testing.T) {
+	assert.Equal(t, []string{"adversarial", "classic", "hard"}, SpawnNames())
+	for _, name := range SpawnNames() {
+		policy, err := SpawnPreset(name)
+		assert.Nil(t, err)
+		assert.Nil(t, policy.validate(16), name)
+	}
+	_, err := SpawnPreset("easy")
+	assert.NotNil(t, err)
+
+	// presets can not be changed through the policy returned
+	policy, _ := SpawnPreset("classic")
+	policy.Weights[0] = 0
+	policy, _ = SpawnPreset("classic")
+	assert.Equal(t, []int{9, 1}, policy.Weights)
+}
+
+func TestSpawnPolicy_validate(t *testing.T) {
+	for _, policy := range []SpawnPolicy{
+		{Weights: []int{}, Initial: 2, PerMove: 1},
+		{Weights: []int{1, 1, 1, 1, 1}, Initial: 2, PerMove: 1},
+		{Weights: []int{0, 0}, Initial: 2, PerMove: 1},
+		{Weights: []int{1, -1}, Initial: 2, PerMove: 1},
+		{Weights: []int{1}, Initial: 0, PerMove: 1},
+		{Weights: []int{1}, Initial: 10, PerMove: 1},
+		{Weights: []int{1}, Initial: 2, PerMove: 0},
+	} {
+		assert.NotNil(t, policy.validate(9), "%+v", policy)
+	}
+	_, err := New(Options{Width: 4, Height: 4, Spawn: SpawnPolicy{Weights: []int{1, 1, 1}, Initial: 2, PerMove: 1}, Target: 8})
+	assert.NotNil(t, err, "target must be above the largest new number")
+}
+
+func TestGame_spawnElement(t *testing.T) {
+	g, _ := New(DefaultOptions())
+	twos := 0
+	for i := 0; i < 10000; i++ {
+		switch g.spawnElement() {
+		case 1:
+			twos++
+		case 2:
+		default:
+			t.Fatal("classic spawns 2 and 4 only")
+		}
+	}
+	assert.InDelta(t, 9000, twos, 300, "90% 2s")
+}
+
+func TestGame_spawnCount(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Spawn.Initial = 4
+	g, err := New(opts)
+	assert.Nil(t, err)
+	assert.Equal(t, 12, g.boardFree)
+	assert.Equal(t, 12, countFree(g))
+
+	opts.Spawn, _ = SpawnPreset("hard")
+	g, _ = New(opts)
+	copy(g.board, []int{
+		1, 1, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+	})
+	g.boardFree = 14
+	moved, _ := g.Move(Left)
+	assert.True(t, moved)
+	assert.Equal(t, 13, g.boardFree, "merged one, spawned two")
+	assert.Equal(t, 13, countFree(g))
+}
+
+func countFree(g *Game) int {
+	free := 0
+	for _, e := range g.board {
+		if e == emptyElement {
+			free++
+		}
+	}
+	return free
+}
+
+func TestGame_worstCell(t *testing.T) {
+	g := newTestGame(3, 3, []int{
+		1, 0, 0,
+		0, 0, 0,
+		0, 0, 0,
+	})
+	// a 2 in line with the other could be merged, leaving more free cells
+	assert.Equal(t, 4, g.worstCell(1))
+
+	g.spawnPolicy = SpawnPolicy{Weights: []int{1}, Initial: 2, PerMove: 1, Adversarial: true}
+	g.spawn(1)
+	assert.Equal(t, 4, g.lastNewNumberIndex)
+	assert.Equal(t, 7, g.boardFree)
+}
@@ -0,0 +1,50 @@
+package game2048
+
+// undoState is the game before a valid move
+type undoState struct {
+	board            []int
+	score, boardFree int
+	rngState         uint64
+}
+
+// remember the game before a move
+func (g *Game) pushHistory() {
+	g.history = append(g.history, undoState{
+		board:     append([]int(nil), g.board...),
+		score:     g.score,
+		boardFree: g.boardFree,
+		rngState:  g.rngSource.State(),
+	})
+}
+
+// forget the state pushed before an invalid move, or the oldest states
+// beyond undoLimit after a valid one, no more can be undone
+func (g *Game) trimHistory() {
+	if !g.lastMoveValid {
+		g.history = g.history[:len(g.history)-1]
+	} else if g.undoLimit >= 0 && len(g.history) > g.undoLimit {
+		g.history = g.history[len(g.history)-g.undoLimit:]
+	}
+}
+
+// Undo take back the last move, the same number appears again if it is
+// moved the same way. false if no move can be undone or the undos of the
+// game are used up.
+func (g *Game) Undo() bool {
+	if len(g.history) == 0 || (g.undoLimit >= 0 && g.undosUsed >= g.undoLimit) {
+		return false
+	}
+	last := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+	copy(g.board, last.board)
+	g.score, g.boardFree = last.score, last.boardFree
+	g.rngSource.SetState(last.rngState)
+	g.undosUsed++
+	g.checkAlive()
+	return true
+}
+
+// UndosUsed in current game
+func (g *Game) UndosUsed() int {
+	return g.undosUsed
+}
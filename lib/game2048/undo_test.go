@@ -0,0 +1,90 @@
+package game2048
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGame_Undo(t *testing.T) {
+	g := newTestGame(4, 4, []int{
+		1, 1, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+	})
+	g.undoLimit = -1
+	before := g.Clone()
+	moved, _ := g.Move(Left)
+	assert.True(t, moved)
+	after := g.Clone()
+	moved, _ = g.Move(Right)
+	assert.True(t, moved)
+
+	assert.True(t, g.Undo())
+	assert.Equal(t, after.board, g.board)
+	assert.True(t, g.Undo())
+	assert.Equal(t, before.board, g.board)
+	assert.Equal(t, before.score, g.score)
+	assert.Equal(t, before.boardFree, g.boardFree)
+	assert.False(t, g.Undo(), "nothing left to undo")
+	assert.Equal(t, 2, g.UndosUsed())
+
+	// the same number appears again
+	g.Move(Left)
+	assert.Equal(t, after.board, g.board)
+}
+
+func TestGame_UndoLimit(t *testing.T) {
+	board := []int{
+		1, 1, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+	}
+	g := newTestGame(4, 4, board)
+	g.undoLimit = 0
+	g.Move(Left)
+	assert.False(t, g.Undo(), "undo is off")
+
+	g = newTestGame(4, 4, board)
+	g.undoLimit = 1
+	g.Move(Left)
+	g.Move(Right)
+	assert.True(t, g.Undo())
+	assert.False(t, g.Undo(), "only the last move is kept")
+	assert.Equal(t, 1, g.UndosUsed())
+	g.Move(Left)
+	assert.False(t, g.Undo(), "one undo per game")
+
+	g = newTestGame(3, 3, []int{
+		3, 4, 3,
+		4, 3, 4,
+		4, 3, 0,
+	})
+	g.undoLimit = 1
+	g.Move(Right)
+	moved, _ := g.Move(Left)
+	assert.False(t, moved)
+	assert.True(t, g.Undo(), "invalid moves do not push out the last state")
+
+	g.Restart()
+	assert.Equal(t, 0, g.UndosUsed())
+	assert.False(t, g.Undo())
+}
+
+func TestRun_undo(t *testing.T) {
+	g := newTestGame(3, 3, []int{
+		3, 4, 3,
+		4, 3, 4,
+		4, 3, 0,
+	})
+	g.undoLimit = 1
+	records, frames := runInputs(g, keyUndo, keyRight, keyUndo)
+	if assert.Len(t, frames, 3, "undo without history draws nothing") {
+		assert.Equal(t, screenOver, frames[1].screen)
+		assert.Equal(t, screenPlaying, frames[2].screen, "undone game over")
+		assert.Equal(t, 1, frames[2].undos)
+	}
+	assert.Equal(t, 1, records[0].UndosUsed)
+}
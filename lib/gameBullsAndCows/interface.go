@@ -24,35 +24,35 @@ func ListenToInput(g Game, stopCh chan struct{}) {
 		if ev.Type == termbox.EventError {
 			panic(ev.Err)
 		}
-		if ev.Type == termbox.EventKey {
-			switch ev.Key {
-			case termbox.KeyArrowLeft:
+		if action, ok := bindings.Action(ev); ok {
+			switch action {
+			case actionLeft:
 				im.cursor--
 				if im.cursor < 0 {
 					im.cursor = len(im.guess) - 1
 				}
-			case termbox.KeyArrowRight:
+			case actionRight:
 				im.cursor++
 				if im.cursor >= len(im.guess) {
 					im.cursor = 0
 				}
-			case termbox.KeyArrowUp:
+			case actionIncrease:
 				im.guess[im.cursor]++
 				if im.guess[im.cursor] > 9 {
 					im.guess[im.cursor] = 0
 				}
-			case termbox.KeyArrowDown:
+			case actionDecrease:
 				im.guess[im.cursor]--
 				if im.guess[im.cursor] < 0 {
 					im.guess[im.cursor] = 9
 				}
-			case termbox.KeyEnter:
+			case actionGuess:
 				if g.guess(im.guess) {
 					close(stopCh)
 					fmt.Println("You win!")
 					return
 				}
-			case termbox.KeyEsc:
+			case actionQuit:
 				close(stopCh)
 				return
 			}
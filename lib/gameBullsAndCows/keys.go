@@ -0,0 +1,57 @@
+package gameBullsAndCows
+
+import "github.com/SpicyChickenFLY/tiny-games-go/lib/keymap"
+
+// keymap section of bulls and cows in config file
+const keymapSection = "bullsAndCows"
+
+// keymap actions
+const (
+	actionLeft     = "left"
+	actionRight    = "right"
+	actionIncrease = "increase"
+	actionDecrease = "decrease"
+	actionGuess    = "guess"
+	actionQuit     = "quit"
+)
+
+var defaultKeymap = keymap.MustNew(map[string][]string{
+	actionLeft:     {"Left"},
+	actionRight:    {"Right"},
+	actionIncrease: {"Up"},
+	actionDecrease: {"Down"},
+	actionGuess:    {"Enter"},
+	actionQuit:     {"Esc"},
+})
+
+var keymapPresets = map[string]*keymap.Keymap{
+	keymap.DefaultPreset: defaultKeymap,
+	"wasd": defaultKeymap.With(map[string][]string{
+		actionLeft:     {"a", "Left"},
+		actionRight:    {"d", "Right"},
+		actionIncrease: {"w", "Up"},
+		actionDecrease: {"s", "Down"},
+		actionGuess:    {"Enter", "Space"},
+	}),
+	"vim": defaultKeymap.With(map[string][]string{
+		actionLeft:     {"h", "Left"},
+		actionRight:    {"l", "Right"},
+		actionIncrease: {"k", "Up"},
+		actionDecrease: {"j", "Down"},
+		actionQuit:     {"q", "Esc"},
+	}),
+}
+
+// key bindings used by ListenToInput
+var bindings = defaultKeymap
+
+// LoadKeymap load key bindings of bulls and cows from config file, a
+// missing file keeps the default keys
+func LoadKeymap(path string) error {
+	km, err := keymap.LoadFile(path, keymapSection, keymapPresets)
+	if err != nil {
+		return err
+	}
+	bindings = km
+	return nil
+}
@@ -0,0 +1,105 @@
+package gameTetris
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Board is the size of playfield in minos, and of minos on screen
+type Board struct {
+	Width, Height, BufferHeight, VisibleBufferHeight int
+	Scale                                            int
+}
+
+var boardPresets = map[string]Board{
+	"standard": {Width: 10, Height: 20, BufferHeight: 20, VisibleBufferHeight: 2, Scale: 1},
+	"narrow":   {Width: 4, Height: 20, BufferHeight: 20, VisibleBufferHeight: 2, Scale: 1},
+	"tall":     {Width: 10, Height: 40, BufferHeight: 40, VisibleBufferHeight: 2, Scale: 1},
+	// Big mode: 2x minos on the screen space of a standard playfield
+	"big": {Width: 5, Height: 10, BufferHeight: 10, VisibleBufferHeight: 1, Scale: 2},
+}
+
+// BoardPreset by name, see BoardNames
+func BoardPreset(name string) (Board, error) {
+	board, ok := boardPresets[name]
+	if !ok {
+		return Board{}, fmt.Errorf("unknown board %q, want one of %v", name, BoardNames())
+	}
+	return board, nil
+}
+
+// BoardNames of presets, sorted
+func BoardNames() []string {
+	names := make([]string, 0, len(boardPresets))
+	for name := range boardPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetupBoard change the playfield size, takes effect from next game
+func (gm *GameManager) SetupBoard(board Board) error {
+	setups := gm.GetSetups()
+	setups.Width, setups.Height = board.Width, board.Height
+	setups.BufferHeight, setups.VisibleBufferHeight = board.BufferHeight, board.VisibleBufferHeight
+	setups.Scale = board.Scale
+	return gm.Setup(setups)
+}
+
+// screen layout: statistics | playfield | next and score
+const (
+	leftPanelWidth  = 24
+	rightPanelWidth = 28
+	fieldMargin     = 2
+)
+
+// layout places a frame on a screen of screenW x screenH cells,
+// 0 means unknown and unlimited
+type layout struct {
+	// statistics are dropped when the screen is too narrow
+	stats          bool
+	fieldX, panelX int
+	scale          int
+	// playfield rows [rowOffset, rowOffset+rows) are shown, taller
+	// playfields scroll to keep the current tetrimino (or where it
+	// spawns) and the top of the stack in sight, the tetrimino first
+	rowOffset, rows int
+}
+
+func newLayout(frame Frame, screenW, screenH int) layout {
+	l := layout{stats: true, fieldX: leftPanelWidth, scale: frameScale(frame), rows: frame.Height}
+	fieldW := frame.Width * 2 * l.scale
+	if screenW > 0 && leftPanelWidth+fieldW+fieldMargin+rightPanelWidth > screenW {
+		l.stats, l.fieldX = false, 0
+	}
+	l.panelX = l.fieldX + fieldW + fieldMargin
+	// row 0 of screen is left blank above the playfield
+	if screenH > 0 && l.rows*l.scale > screenH-1 {
+		l.rows = (screenH - 1) / l.scale
+		if l.rows < 1 {
+			l.rows = 1
+		}
+		top := frame.PieceTop
+		if frame.StackTop > top && frame.StackTop-l.rows < frame.PieceTop {
+			top = frame.StackTop
+		}
+		l.rowOffset = top - l.rows
+		if l.rowOffset > frame.Height-l.rows {
+			l.rowOffset = frame.Height - l.rows
+		}
+		if l.rowOffset < 0 {
+			l.rowOffset = 0
+		}
+	}
+	return l
+}
+
+// screen position of the top-left cell of tile in row i (from the
+// bottom), column j, ok is false if the row is scrolled out
+func (l layout) cell(i, j int) (x, y int, ok bool) {
+	if i < l.rowOffset || i >= l.rowOffset+l.rows {
+		return 0, 0, false
+	}
+	return l.fieldX + j*2*l.scale, 1 + (l.rowOffset+l.rows-1-i)*l.scale, true
+}
@@ -0,0 +1,177 @@
+package gameTetris
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpawnX(t *testing.T) {
+	assert.Equal(t, 3, spawnX(defaultWidth), "guideline spawn columns")
+	for width := tetriNum; width <= maxWidth; width++ {
+		for idx, shapes := range tetriminoShapes {
+			minX, maxX := width, -1
+			for i := shapes[0]; i != 0; i >>= tetriNum {
+				x := spawnX(width) + i&0xF%tetriNum
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+			}
+			assert.True(t, minX >= 0 && maxX < width, "%c spawns inside width %d", tetriminoNames[idx], width)
+			left, right := minX, width-1-maxX
+			assert.True(t, left-right <= 1 && right-left <= 2, "%c is centered on width %d", tetriminoNames[idx], width)
+		}
+	}
+}
+
+func TestBoardPresets(t *testing.T) {
+	_, err := BoardPreset("huge")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"big", "narrow", "standard", "tall"}, BoardNames())
+	for _, name := range BoardNames() {
+		board, err := BoardPreset(name)
+		assert.Nil(t, err)
+		// every preset is playable
+		ops := make([]int, 3)
+		for i := range ops {
+			ops[i] = hardDrop
+		}
+		g := newScriptedGameManager(ops...)
+		assert.Nil(t, g.SetupBoard(board), name)
+		g.Practice(-1)
+		assert.True(t, g.QuitRequested(), name)
+		assert.Equal(t, board.Width, g.frame().Width, name)
+		assert.Equal(t, board.Scale, g.frame().Scale, name)
+		assert.Greater(t, g.Statistics().Pieces, 0, name)
+	}
+}
+
+func TestSetups_validateBoard(t *testing.T) {
+	g := newTestGameManager()
+	for _, board := range []Board{
+		{Width: tetriNum - 1, Height: 20, BufferHeight: 20, Scale: 1},
+		{Width: maxWidth + 1, Height: 20, BufferHeight: 20, Scale: 1},
+		{Width: 10, Height: maxHeight + 1, BufferHeight: 20, Scale: 1},
+		{Width: 10, Height: 20, BufferHeight: maxHeight + 1, Scale: 1},
+		{Width: 10, Height: 20, BufferHeight: 20, Scale: 0},
+		{Width: 10, Height: 20, BufferHeight: 20, Scale: maxScale + 1},
+	} {
+		assert.NotNil(t, g.SetupBoard(board), "%+v", board)
+	}
+	assert.Equal(t, boardPresets["standard"], Board{
+		Width: g.width, Height: g.height, BufferHeight: g.bufferHeight,
+		VisibleBufferHeight: g.visibleBufferHeight, Scale: g.scale,
+	}, "invalid boards are not applied")
+}
+
+func TestNewLayout(t *testing.T) {
+	frame := Frame{Width: 10, Height: 22, Playfield: make([]int, 10*22)}
+	l := newLayout(frame, 80, 24)
+	assert.True(t, l.stats)
+	assert.Equal(t, leftPanelWidth+20+fieldMargin, l.panelX)
+	x, y, ok := l.cell(0, 0)
+	assert.True(t, ok)
+	assert.Equal(t, leftPanelWidth, x)
+	assert.Equal(t, 22, y, "bottom row")
+
+	// wide boards drop statistics
+	frame = Frame{Width: 20, Height: 20, Playfield: make([]int, 20*20)}
+	l = newLayout(frame, 80, 24)
+	assert.False(t, l.stats)
+	assert.Equal(t, 0, l.fieldX)
+	assert.Equal(t, 40+fieldMargin, l.panelX)
+
+	// Big mode doubles minos
+	frame = Frame{Width: 5, Height: 11, Scale: 2, Playfield: make([]int, 5*11)}
+	l = newLayout(frame, 80, 24)
+	x, y, _ = l.cell(0, 4)
+	assert.Equal(t, leftPanelWidth+16, x)
+	assert.Equal(t, 21, y, "top cell of bottom row")
+	assert.Equal(t, leftPanelWidth+20+fieldMargin, l.panelX)
+}
+
+func TestNewLayout_scroll(t *testing.T) {
+	frame := Frame{Width: 10, Height: 42, Playfield: make([]int, 10*42)}
+	l := newLayout(frame, 80, 24)
+	assert.Equal(t, 23, l.rows)
+	assert.Equal(t, 0, l.rowOffset, "empty playfield shows the bottom")
+
+	// the higher of tetrimino and stack is kept on the top row
+	frame.StackTop, frame.PieceTop = 20, 30
+	l = newLayout(frame, 80, 24)
+	assert.Equal(t, 30-23, l.rowOffset)
+	_, y, ok := l.cell(29, 4)
+	assert.True(t, ok)
+	assert.Equal(t, 1, y)
+	_, _, ok = l.cell(19, 4)
+	assert.True(t, ok, "stack is in sight")
+	_, _, ok = l.cell(0, 0)
+	assert.False(t, ok, "bottom is scrolled out")
+
+	frame.StackTop, frame.PieceTop = 30, 20
+	l = newLayout(frame, 80, 24)
+	assert.Equal(t, 30-23, l.rowOffset)
+
+	// the tetrimino wins when both do not fit
+	frame.StackTop, frame.PieceTop = 10, 41
+	l = newLayout(frame, 80, 24)
+	assert.Equal(t, 41-23, l.rowOffset)
+	frame.StackTop, frame.PieceTop = 40, 5
+	l = newLayout(frame, 80, 24)
+	assert.Equal(t, 0, l.rowOffset)
+
+	// but the view stays on the playfield
+	frame.StackTop, frame.PieceTop = 0, 44
+	l = newLayout(frame, 80, 24)
+	assert.Equal(t, 42-23, l.rowOffset)
+
+	// unknown screen size shows everything
+	l = newLayout(frame, 0, 0)
+	assert.Equal(t, 42, l.rows)
+	assert.Equal(t, 0, l.rowOffset)
+}
+
+// tetriminos are seen as they spawn on a tall board
+func TestNewLayout_scrollToSpawn(t *testing.T) {
+	g := newTestGameManager()
+	board, _ := BoardPreset("tall")
+	assert.NoError(t, g.SetupBoard(board))
+	g.reload()
+	g.playfield[4] = garbageTile
+	frame := g.frame()
+	assert.Equal(t, 1, frame.StackTop)
+	l := newLayout(frame, 80, 24)
+	_, _, ok := l.cell(g.tetriminoSpawnY, 4)
+	assert.True(t, ok, "spawn row is in sight before a tetrimino spawns")
+
+	assert.True(t, g.generationPhase())
+	frame = g.frame()
+	l = newLayout(frame, 80, 24)
+	for i := tetriminoShapes[g.tetriminoIdx][g.tetriminoDrct]; i != 0; i >>= tetriNum {
+		x, y := g.calcMinoPosOnBoard(i)
+		_, _, ok = l.cell(y, x)
+		assert.True(t, ok, "mino at row %d is in sight", y)
+	}
+
+	// the stack comes into sight as the tetrimino falls to it
+	g.tetriminoY = 15
+	l = newLayout(g.frame(), 80, 24)
+	_, _, ok = l.cell(0, 4)
+	assert.True(t, ok, "stack is in sight")
+}
+
+func TestANSIRenderer_RenderBig(t *testing.T) {
+	g := newTestGameManager()
+	board, _ := BoardPreset("big")
+	assert.Nil(t, g.SetupBoard(board))
+	g.reload()
+	buf := &bytes.Buffer{}
+	NewANSIRenderer(buf).Render(g.frame())
+	lines := bytes.Split(buf.Bytes(), []byte("\r\n"))
+	assert.Equal(t, (board.Height+board.VisibleBufferHeight)*2+1, len(lines)-1)
+	assert.Equal(t, "+"+string(bytes.Repeat([]byte("--"), board.Width*2))+"+", string(lines[len(lines)-2]))
+}
@@ -4,13 +4,16 @@ import (
 	"math"
 	"math/rand"
 	"time"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/rng"
 )
 
 const (
-	emptyTile = 0
-	tetriNum  = 4
-	rowEmpty  = -2
-	rowFull   = -1
+	emptyTile   = 0
+	garbageTile = 8
+	tetriNum    = 4
+	rowEmpty    = -2
+	rowFull     = -1
 )
 
 const (
@@ -19,9 +22,13 @@ const (
 	defaultLockDownDelay           = 500
 	defaultHeight                  = 20
 	defaultBufferHeight            = 20
+	defaultVisibleBufferHeight     = 2
 	defaultWidth                   = 10
+	defaultScale                   = 1
 	defaultDropSpeedRatio          = 20
 	defaultAllowSRS                = true
+	defaultAllowRotate180          = true
+	defaultRotate180Kicks          = Rotate180SRSPlus
 	defaultAllowGhost              = true
 	defaultAllowHardDropOp         = false
 	defaultAllowLockDownPeek       = true
@@ -30,6 +37,14 @@ const (
 	defaultAllowTopOut             = false // for now, this must be false
 	defaultAllowLockOut            = false
 	defaultAllowBlockOut           = false // for now, this must be false
+	maxSpeedLevel                  = 20
+)
+
+// limits of playfield dimensions, in minos
+const (
+	maxWidth  = 40
+	maxHeight = 200
+	maxScale  = 4
 )
 
 // operation type (Chapter 4)
@@ -40,7 +55,29 @@ const (
 	hardDrop
 	rotateClockwise
 	rotateCounterClockwise
+	rotate180
 	hold
+	retry
+	pause
+	undoLock      // practice only
+	toggleGravity // practice only
+	chooseNext    // practice only, chooseNext+idx picks tetrimino idx
+)
+
+// pause menu items, chosen with rotateClockwise (up), softDrop (down)
+// and hardDrop (select)
+const (
+	pauseMenuResume = iota
+	pauseMenuRestart
+	pauseMenuQuit
+	pauseMenuItemNum
+)
+
+// 180 degree kick tables, choosen by Setups.Rotate180Kicks
+const (
+	Rotate180SRSPlus = iota
+	Rotate180TetrisOnline
+	rotate180KicksNum
 )
 
 const (
@@ -70,8 +107,12 @@ var tetriminoShapes = [7][4]int{
 	{0x6510, 0x9652, 0xA954, 0x8541}, // Z-tetrimino
 }
 
+// corners around the center of T-tetrimino
 var tSpinCheckMino = 0x8A20
 
+// corners on the pointing side of T-tetrimino in each facing
+var tSpinFrontCorners = [4][2]int{{0x0, 0x2}, {0x2, 0xA}, {0x8, 0xA}, {0x0, 0x8}}
+
 const kickWallTableTestCaseNum = 5
 
 var kickWallTableForJLSTZ = [][kickWallTableTestCaseNum][2]int{
@@ -103,113 +144,182 @@ var kickWallTableForI = [][kickWallTableTestCaseNum][2]int{
 
 }
 
+// SRS+ 180 kicks (TETR.IO), shared by all tetriminos
+var kickWallTable180SRSPlus = [][][2]int{
+	{{0, 0}, {0, +1}, {+1, +1}, {-1, +1}, {+1, 0}, {-1, 0}}, // 0->2
+	{{0, 0}, {+1, 0}, {+1, +2}, {+1, +1}, {0, +2}, {0, +1}}, // R->L
+	{{0, 0}, {0, -1}, {-1, -1}, {+1, -1}, {-1, 0}, {+1, 0}}, // 2->0
+	{{0, 0}, {-1, 0}, {-1, +2}, {-1, +1}, {0, +2}, {0, +1}}, // L->R
+}
+
+// Tetris Online 180 kicks
+var kickWallTable180TetrisOnlineForJLSTZ = [][][2]int{
+	{{0, 0}, {+1, 0}, {+2, 0}, {+1, -1}, {+2, -1}, {-1, 0}, {-2, 0}, {-1, -1}, {-2, -1}, {0, +1}, {+3, 0}, {-3, 0}}, // 0->2
+	{{0, 0}, {0, -1}, {0, -2}, {-1, -1}, {-1, -2}, {0, +1}, {0, +2}, {-1, +1}, {-1, +2}, {+1, 0}, {0, -3}, {0, +3}}, // R->L
+	{{0, 0}, {-1, 0}, {-2, 0}, {-1, +1}, {-2, +1}, {+1, 0}, {+2, 0}, {+1, +1}, {+2, +1}, {0, -1}, {-3, 0}, {+3, 0}}, // 2->0
+	{{0, 0}, {0, -1}, {0, -2}, {+1, -1}, {+1, -2}, {0, +1}, {0, +2}, {+1, +1}, {+1, +2}, {-1, 0}, {0, -3}, {0, +3}}, // L->R
+}
+
+var kickWallTable180TetrisOnlineForI = [][][2]int{
+	{{0, 0}, {-1, 0}, {-2, 0}, {+1, 0}, {+2, 0}, {0, -1}}, // 0->2
+	{{0, 0}, {0, -1}, {0, -2}, {0, +1}, {0, +2}, {-1, 0}}, // R->L
+	{{0, 0}, {+1, 0}, {+2, 0}, {-1, 0}, {-2, 0}, {0, +1}}, // 2->0
+	{{0, 0}, {0, -1}, {0, -2}, {0, +1}, {0, +2}, {+1, 0}}, // L->R
+}
+
 // GameManager implement GameManager interface
 type GameManager struct {
 	// game optional variables(can be modified before game start)
 	difficulty, lockDownDelay                  int
 	height, bufferHeight, width, stashQueueCap int
+	visibleBufferHeight, scale                 int
 	dropSpeedRatio                             float64
+	rotate180Kicks                             int
 	allowSRS, allowGhost, allowHardDropOp      bool
+	allowRotate180                             bool
 	allowLockDownPeek, allowPlayAboveSkyline   bool
 	allowForcedAboveSkyline                    bool
 	allowTopOut, allowLockOut, allowBlockOut   bool
 
 	// game internal variables(can not be modified by user)
 	playfield                                                []int
-	bag, nextBag                                             []int
+	bag, nextBag, presetQueue                                []int
 	stashQueue                                               []int
 	tetriminoX, tetriminoY, tetriminoSpawnX, tetriminoSpawnY int
 	tetriminoIdx, tetriminoDrct, nextTetriminoIdx            int
-	ghostX, ghostY, bagIdx, lastOp                           int
+	ghostX, ghostY, bagIdx, lastOp, lastKick                 int
 	softDropLine, hardDropLine, score, highScore, level      int
 	tSpinCount, tetrisCount, comboCount                      int
-	// tpm, lpm                                                 int
-	fallSpeed                                          float64
-	hardDropFlag, softDropFlag, moveFlag               bool
-	landFlag, lockDownTimerResetFlag, patternMatchFlag bool
-	tSpinFlag, miniSpinFlag, backToBackFlag            bool
-	startTime                                          time.Time
+	clearedLines, lastClearLines                             int
+	finesseInputs, finesseFaults                             int
+	attack, maxComboCount                                    int
+	pieceCounts                                              [len(tetriminoShapes)]int
+	clearCounts                                              [clearTypeNum]int
+	fallSpeed                                                float64
+	hardDropFlag, softDropFlag, moveFlag, activeFlag         bool
+	landFlag, lockDownTimerResetFlag, patternMatchFlag       bool
+	tSpinFlag, miniSpinFlag, backToBackFlag                  bool
+	endFlag, retryFlag, puzzleSolvedFlag                     bool
+	finesseSkipFlag, pausedFlag, quitFlag                    bool
+	pauseMenuIdx                                             int
+	startTime, pauseStartTime                                time.Time
+	pausedDuration, resumedDuration                          time.Duration
+	puzzle                                                   *Puzzle
+	practiceFlag, gravityOffFlag                             bool
+	marathon                                                 *Marathon
+	levelLines                                               int
+	marathonClearedFlag                                      bool
+	undoLimit                                                int
+	history                                                  []snapshot
+	rngSource                                                *rng.Source
+	rng                                                      *rand.Rand
 
 	// io utils
 	inputCh  chan int
-	renderer func(playfield, next []int, height, width, score, highScore, level,
-		tSpinCount, tetrisCount, comboCount int)
+	eventCh  chan<- Event
+	renderer Renderer
 }
 
 // NewGameManager return *GameManager
-func NewGameManager(
-	inputCh chan int,
-	renderer func(
-		playfield, next []int, height, width, score, highScore, level,
-		tSpinCount, tetrisCount, comboCount int),
-) *GameManager {
-	return &GameManager{
-		difficulty:              defaultDifficulty,
-		lockDownDelay:           defaultLockDownDelay,
-		height:                  defaultHeight,
-		bufferHeight:            defaultBufferHeight,
-		width:                   defaultWidth,
-		dropSpeedRatio:          defaultDropSpeedRatio,
-		allowSRS:                defaultAllowSRS,
-		allowGhost:              defaultAllowGhost,
-		allowHardDropOp:         defaultAllowHardDropOp,
-		allowLockDownPeek:       defaultAllowLockDownPeek,
-		allowPlayAboveSkyline:   defaultAllowPlayAboveSkyline,
-		allowForcedAboveSkyline: defaultAllowForcedAboveSkyline,
-		allowTopOut:             defaultAllowTopOut,
-		allowLockOut:            defaultAllowLockOut,
-		allowBlockOut:           defaultAllowBlockOut,
-		inputCh:                 inputCh,
-		renderer:                renderer,
+func NewGameManager(inputCh chan int, renderer Renderer) *GameManager {
+	gm := &GameManager{
+		inputCh:  inputCh,
+		renderer: renderer,
 	}
+	gm.RestoreDefaultSetup()
+	gm.Seed(time.Now().UnixNano())
+	return gm
 }
 
 // ================ Utils ====================
 
+// spawnX centers 3-wide tetriminos, rounding left, while I and O stay
+// inside playfields as narrow as tetriNum
+func spawnX(width int) int {
+	return (width - 3) / 2
+}
+
 func (gm *GameManager) reload() {
-	rand.Seed(time.Now().UnixNano())
 	gm.playfield = make([]int, gm.width*(gm.height+gm.bufferHeight))
-	gm.tetriminoSpawnX = (gm.width - tetriNum) / 2
+	gm.tetriminoSpawnX = spawnX(gm.width)
 	gm.tetriminoSpawnY = gm.height
 	gm.bag = make([]int, len(tetriminoShapes))
 	gm.nextBag = make([]int, len(tetriminoShapes))
-	// use bag system twice to ensure current bag is shuffled
-	gm.bagIdx = len(gm.bag)
-	gm.useBagSystem()
-	gm.bagIdx = len(gm.bag)
-	gm.useBagSystem()
+	// shift bag twice to ensure both bags are shuffled
+	gm.shiftBag()
+	gm.shiftBag()
+	gm.bagIdx = -1
+	gm.presetQueue = nil
+	gm.puzzle = nil
+	gm.practiceFlag, gm.gravityOffFlag = false, false
+	gm.undoLimit, gm.history = 0, nil
+	gm.marathon, gm.levelLines, gm.marathonClearedFlag = nil, 0, false
+	gm.updateNextTetrimino()
 	gm.stashQueue = make([]int, gm.stashQueueCap)
+	gm.activeFlag = false
+	gm.endFlag, gm.retryFlag, gm.puzzleSolvedFlag = false, false, false
+	gm.pausedFlag, gm.quitFlag = false, false
+	gm.pausedDuration, gm.resumedDuration = 0, 0
 
 	gm.softDropLine = 0
 	gm.hardDropLine = 0
-	gm.score = 0
+	gm.score, gm.level = 0, 1
+	gm.backToBackFlag = false
 	gm.tSpinCount = 0
 	gm.tetrisCount = 0
 	gm.comboCount = -1
+	gm.clearedLines, gm.lastClearLines = 0, 0
+	gm.finesseFaults = 0
+	gm.resetStatistics()
 	gm.calcFallSpeed()
 }
 
-// get from bag system (A 1.2.1)
+// move nextBag into bag and shuffle a new nextBag (A 1.2.1)
+func (gm *GameManager) shiftBag() {
+	copy(gm.bag, gm.nextBag)
+	for i := 0; i < len(gm.nextBag); i++ {
+		gm.nextBag[i] = i
+	}
+	gm.rng.Shuffle(
+		len(gm.nextBag),
+		func(i, j int) {
+			gm.nextBag[i], gm.nextBag[j] = gm.nextBag[j], gm.nextBag[i]
+		})
+}
+
+// get from preset queue first, then from bag system (A 1.2.1)
 func (gm *GameManager) useBagSystem() {
-	gm.bagIdx++
-	if gm.bagIdx >= len(gm.bag) {
-		copy(gm.bag, gm.nextBag)
-		for i := 0; i < len(gm.nextBag); i++ {
-			gm.nextBag[i] = i
+	if len(gm.presetQueue) > 0 {
+		gm.tetriminoIdx = gm.presetQueue[0]
+		gm.presetQueue = gm.presetQueue[1:]
+	} else {
+		gm.bagIdx++
+		if gm.bagIdx >= len(gm.bag) {
+			gm.shiftBag()
+			gm.bagIdx = 0
 		}
-		rand.Shuffle(
-			len(gm.nextBag),
-			func(i, j int) {
-				gm.nextBag[i], gm.nextBag[j] = gm.nextBag[j], gm.nextBag[i]
-			})
-		gm.bagIdx = 0
+		gm.tetriminoIdx = gm.bag[gm.bagIdx]
 	}
-	gm.tetriminoIdx = gm.bag[gm.bagIdx]
+	gm.updateNextTetrimino()
+}
 
-	if gm.bagIdx == len(gm.bag)-1 {
-		gm.nextTetriminoIdx = gm.nextBag[0]
-	} else {
-		gm.nextTetriminoIdx = gm.bag[gm.bagIdx+1]
+// all tetriminos known to come after the current one, in order
+// (a puzzle only deals its own sequence)
+func (gm *GameManager) upcomingTetriminos() []int {
+	upcoming := make([]int, 0, len(gm.presetQueue)+len(gm.bag)+len(gm.nextBag))
+	upcoming = append(upcoming, gm.presetQueue...)
+	if gm.puzzle != nil {
+		return upcoming
+	}
+	upcoming = append(upcoming, gm.bag[gm.bagIdx+1:]...)
+	upcoming = append(upcoming, gm.nextBag...)
+	return upcoming
+}
+
+func (gm *GameManager) updateNextTetrimino() {
+	gm.nextTetriminoIdx = -1
+	if upcoming := gm.upcomingTetriminos(); len(upcoming) > 0 {
+		gm.nextTetriminoIdx = upcoming[0]
 	}
 }
 
@@ -251,10 +361,23 @@ func (gm *GameManager) calcGhostPos() {
 	}
 }
 
+// game clock, which stands still while paused
+func (gm *GameManager) now() time.Time {
+	now := time.Now()
+	if gm.pausedFlag {
+		now = gm.pauseStartTime
+	}
+	return now.Add(-gm.pausedDuration)
+}
+
 // calculate the fall speed in current level (unit: Millisecond Per Line)
 func (gm *GameManager) calcFallSpeed() {
 	// TODO: can we modify these ratio?
-	gm.fallSpeed = math.Pow(0.8-float64(gm.level-1)*0.007, float64(gm.level-1)) * 1000
+	level := gm.level
+	if level > maxSpeedLevel {
+		level = maxSpeedLevel // formula goes wrong past here
+	}
+	gm.fallSpeed = math.Pow(0.8-float64(level-1)*0.007, float64(level-1)) * 1000
 }
 
 // calculate the soft drop speed in current level (unit: Millisecond Per Line)
@@ -299,40 +422,37 @@ func (gm *GameManager) checkLanding() {
 	gm.landFlag = false
 }
 
-// set postion flag in binary format
-// 0b        0          0          0          0
-// Binary    LeftTop    RightTop   LeftBottom RightBottom
-// 0x3 = 0b0011
-// 0x6 = 0b0110
-// 0x9 = 0b1001
-// 0xC = 0b1100
+// T-Spin recognition (2009 guideline): the last successful movement of
+// T-tetrimino is a rotation and 3 of the 4 corners around its center are
+// occupied, walls and floor included. It is a Mini T-Spin unless both corners
+// on the pointing side are occupied, or the rotation took the last kick of
+// SRS (the 1x2 "TST kick").
 func (gm *GameManager) checkTSpin() {
-	gm.tSpinFlag, gm.miniSpinFlag = false, true
+	gm.tSpinFlag, gm.miniSpinFlag = false, false
 	if gm.tetriminoIdx != tetriminoShapeT ||
 		(gm.lastOp != rotateClockwise &&
-			gm.lastOp != rotateCounterClockwise) {
+			gm.lastOp != rotateCounterClockwise &&
+			gm.lastOp != rotate180) {
 		return
 	}
-	blockedCount, blockedBitFlag := 0, 0
+	blockedCount := 0
 	for i := tSpinCheckMino; i != 0; i >>= tetriNum {
-		x, y := gm.calcMinoPosOnBoard(i)
-		if !gm.checkBorderX(x) || !gm.checkBorderY(y) || gm.playfield[x+y*gm.width] != 0 {
-			blockedBitFlag |= 1
+		if gm.checkCornerBlocked(i) {
 			blockedCount++
 		}
-		blockedBitFlag <<= 1
 	}
-	if blockedCount >= 3 {
-		gm.tSpinFlag = true
-		gm.tSpinCount++
+	if blockedCount < 3 {
+		return
 	}
+	gm.tSpinFlag = true
+	front := tSpinFrontCorners[gm.tetriminoDrct]
+	tstKick := gm.lastOp != rotate180 && gm.lastKick == kickWallTableTestCaseNum-1
+	gm.miniSpinFlag = !tstKick && !(gm.checkCornerBlocked(front[0]) && gm.checkCornerBlocked(front[1]))
+}
 
-	if (gm.tetriminoDrct == 0 && blockedBitFlag&0xC == 0xC && blockedBitFlag&0x3 > 0) ||
-		(gm.tetriminoDrct == 1 && blockedBitFlag&0x6 == 0x6 && blockedBitFlag&0x9 > 0) ||
-		(gm.tetriminoDrct == 2 && blockedBitFlag&0x3 == 0x3 && blockedBitFlag&0xC > 0) ||
-		(gm.tetriminoDrct == 3 && blockedBitFlag&0x9 == 0x9 && blockedBitFlag&0x6 > 0) {
-		gm.miniSpinFlag = false
-	}
+func (gm *GameManager) checkCornerBlocked(posOnShape int) bool {
+	x, y := gm.calcMinoPosOnBoard(posOnShape)
+	return !gm.checkBorderX(x) || !gm.checkBorderY(y) || gm.playfield[x+y*gm.width] != 0
 }
 
 // =============== Basic Operation =================
@@ -358,41 +478,48 @@ func (gm *GameManager) move(opCode int) {
 	gm.moveFlag = true
 }
 
-func (gm *GameManager) rotate(opCode int) {
-	srcDrct := gm.tetriminoDrct
-	// Per rotate
-	if opCode == rotateClockwise {
-		gm.tetriminoDrct++
-		if gm.tetriminoDrct >= len(tetriminoShapes[gm.tetriminoIdx]) {
-			gm.tetriminoDrct = 0
-		}
-	} else {
-		gm.tetriminoDrct--
-		if gm.tetriminoDrct < 0 {
-			gm.tetriminoDrct = len(tetriminoShapes[gm.tetriminoIdx]) - 1
-		}
-	}
-	// check if blocked
-	var testCaseNum int
-	if gm.allowSRS {
-		testCaseNum = kickWallTableTestCaseNum
-	} else {
-		testCaseNum = 1
+// facing after rotating from drct
+func (gm *GameManager) rotatedDrct(drct, opCode int) int {
+	facings := len(tetriminoShapes[gm.tetriminoIdx])
+	switch opCode {
+	case rotateClockwise:
+		return (drct + 1) % facings
+	case rotateCounterClockwise:
+		return (drct + facings - 1) % facings
+	}
+	return (drct + 2) % facings
+}
+
+// kick offsets to test in order when rotating from srcDrct
+func (gm *GameManager) kickTests(srcDrct, opCode int) [][2]int {
+	var testCases [][2]int
+	switch {
+	case opCode == rotate180 && gm.rotate180Kicks == Rotate180TetrisOnline &&
+		gm.tetriminoIdx == tetriminoShapeI:
+		testCases = kickWallTable180TetrisOnlineForI[srcDrct]
+	case opCode == rotate180 && gm.rotate180Kicks == Rotate180TetrisOnline:
+		testCases = kickWallTable180TetrisOnlineForJLSTZ[srcDrct]
+	case opCode == rotate180:
+		testCases = kickWallTable180SRSPlus[srcDrct]
+	case gm.tetriminoIdx == tetriminoShapeI:
+		testCases = kickWallTableForI[srcDrct*2+opCode-rotateClockwise][:]
+	default:
+		testCases = kickWallTableForJLSTZ[srcDrct*2+opCode-rotateClockwise][:]
 	}
-	var testCases [][kickWallTableTestCaseNum][2]int
-	if gm.tetriminoIdx == tetriminoShapeI {
-		testCases = kickWallTableForI
-	} else {
-		testCases = kickWallTableForJLSTZ
+	if !gm.allowSRS {
+		return testCases[:1]
 	}
-	var directionOffset int
-	if opCode == rotateClockwise {
-		directionOffset = 0
-	} else {
-		directionOffset = 1
+	return testCases
+}
+
+func (gm *GameManager) rotate(opCode int) {
+	if opCode == rotate180 && !gm.allowRotate180 {
+		return
 	}
-	for i := 0; i < testCaseNum; i++ {
-		testCase := testCases[srcDrct*2+directionOffset][i]
+	srcDrct := gm.tetriminoDrct
+	gm.tetriminoDrct = gm.rotatedDrct(srcDrct, opCode)
+	// check if blocked
+	for kick, testCase := range gm.kickTests(srcDrct, opCode) {
 		offsetX, offsetY := testCase[0], testCase[1]
 		blockFlag := false
 		for i := tetriminoShapes[gm.tetriminoIdx][gm.tetriminoDrct]; i != 0; i >>= tetriNum {
@@ -411,22 +538,12 @@ func (gm *GameManager) rotate(opCode int) {
 		gm.tetriminoX += offsetX
 		gm.tetriminoY += offsetY
 		gm.calcGhostPos()
-		gm.lastOp = opCode
+		gm.lastOp, gm.lastKick = opCode, kick
 		gm.moveFlag = true
 		return
 	}
 	// not pass any case, rollback
-	if opCode != rotateClockwise {
-		gm.tetriminoDrct++
-		if gm.tetriminoDrct >= len(tetriminoShapes[gm.tetriminoIdx]) {
-			gm.tetriminoDrct = 0
-		}
-	} else {
-		gm.tetriminoDrct--
-		if gm.tetriminoDrct < 0 {
-			gm.tetriminoDrct = len(tetriminoShapes[gm.tetriminoIdx]) - 1
-		}
-	}
+	gm.tetriminoDrct = srcDrct
 }
 
 func (gm *GameManager) softDrop() {
@@ -440,7 +557,10 @@ func (gm *GameManager) softDrop() {
 }
 
 func (gm *GameManager) hardDrop() {
-	gm.lastOp = hardDrop
+	// dropping in place keeps a rotation as the last movement
+	if gm.tetriminoY != gm.ghostY {
+		gm.lastOp = hardDrop
+	}
 	gm.hardDropLine = gm.tetriminoY - gm.ghostY
 	gm.hardDropFlag = true
 	gm.tetriminoX, gm.tetriminoY = gm.ghostX, gm.ghostY
@@ -450,12 +570,24 @@ func (gm *GameManager) hardDrop() {
 
 // Generration Phase (A 1.2.1)
 func (gm *GameManager) generationPhase() bool {
-	// Random Generation of Tetriminos
-	gm.useBagSystem()
-	// Starting Location and Orirntation
-	gm.tetriminoX = gm.tetriminoSpawnX
-	gm.tetriminoY = gm.tetriminoSpawnY
-	gm.tetriminoDrct = 0
+	// a loaded position may already have a tetrimino in play
+	if !gm.activeFlag {
+		// a puzzle fails once its sequence runs out
+		if gm.puzzle != nil && len(gm.presetQueue) == 0 {
+			return false
+		}
+		// Random Generation of Tetriminos
+		gm.useBagSystem()
+		// Starting Location and Orirntation
+		gm.tetriminoX = gm.tetriminoSpawnX
+		gm.tetriminoY = gm.tetriminoSpawnY
+		gm.tetriminoDrct = 0
+		gm.activeFlag = true
+		gm.finesseInputs, gm.finesseSkipFlag = 0, false
+		if gm.practiceFlag {
+			gm.pushHistory()
+		}
+	}
 	gm.calcGhostPos()
 	gm.renderOutput()
 	return gm.checkNoCollision() || gm.allowBlockOut
@@ -465,16 +597,18 @@ func (gm *GameManager) fallingPhase() {
 	gm.checkLanding()
 	for !gm.landFlag {
 		gm.hardDropFlag = false
-		startTime, endTime := time.Now(), time.Now()
-		for endTime.Sub(startTime) < time.Duration(gm.fallSpeed)*time.Millisecond {
+		startTime, endTime := gm.now(), gm.now()
+		for (gm.gravityOffFlag && !gm.softDropFlag) ||
+			endTime.Sub(startTime) < time.Duration(gm.fallSpeed)*time.Millisecond {
 			gm.processInput()
 			gm.calcGhostPos()
-			if gm.hardDropFlag && !gm.allowHardDropOp {
+			if gm.endFlag || (gm.hardDropFlag && !gm.allowHardDropOp) {
 				return
 			}
-			endTime = time.Now()
+			endTime = gm.now()
 		}
 		gm.tetriminoY--
+		gm.lastOp = softDrop // falling is a movement too
 		gm.renderOutput()
 		gm.checkLanding()
 		if gm.softDropFlag {
@@ -485,17 +619,25 @@ func (gm *GameManager) fallingPhase() {
 
 // Lock Phase (A 1.2.1)
 func (gm *GameManager) lockPhase() bool {
-	startTime, endTime := time.Now(), time.Now()
+	if gm.endFlag {
+		return false
+	}
+	startTime, endTime := gm.now(), gm.now()
 	for !gm.hardDropFlag || gm.allowHardDropOp {
-		if endTime.Sub(startTime) >= time.Duration(gm.lockDownDelay)*time.Millisecond {
+		// without gravity only hard drop locks down
+		if !gm.gravityOffFlag &&
+			endTime.Sub(startTime) >= time.Duration(gm.lockDownDelay)*time.Millisecond {
 			break
 		}
 		gm.processInput()
 		gm.calcGhostPos()
+		if gm.endFlag {
+			return false
+		}
 		if gm.moveFlag && gm.landFlag && gm.lockDownTimerResetFlag {
-			startTime = time.Now()
+			startTime = gm.now()
 		}
-		endTime = time.Now()
+		endTime = gm.now()
 	}
 	if gm.moveFlag && !gm.landFlag {
 		return true
@@ -503,7 +645,10 @@ func (gm *GameManager) lockPhase() bool {
 	if !gm.checkNoCollision() || (gm.checkLockOut() && !gm.allowLockOut) {
 		return false
 	}
+	gm.checkFinesse()
 	gm.lockDown() // Lock down this tetrimino
+	gm.pieceCounts[gm.tetriminoIdx]++
+	gm.activeFlag = false
 	gm.checkTSpin()
 	return true
 }
@@ -537,38 +682,30 @@ func (gm *GameManager) animatePhase() {}
 
 func (gm *GameManager) elimatePhase() {
 	clearLineCount := 0
-	for rowIdx := 0; rowIdx < gm.height+gm.bufferHeight; rowIdx++ {
-		rowHeaderPos := rowIdx * gm.width
-		if gm.playfield[rowHeaderPos] == rowFull {
+	rowCount := gm.height + gm.bufferHeight
+	for rowIdx := 0; rowIdx < rowCount; rowIdx++ {
+		// skip full rows, the next remaining row falls to rowIdx
+		for rowIdx+clearLineCount < rowCount &&
+			gm.playfield[(rowIdx+clearLineCount)*gm.width] == rowFull {
 			clearLineCount++
-		} else if gm.playfield[rowHeaderPos] == rowEmpty {
-			gm.playfield[rowHeaderPos] = 0
-			break // no need to move empty line
 		}
-		if clearLineCount > 0 {
-			for colIdx := rowHeaderPos; colIdx < rowHeaderPos+gm.width; colIdx++ {
-				gm.playfield[colIdx] = gm.playfield[colIdx+clearLineCount*gm.width]
-				if gm.playfield[colIdx] < 0 {
-					gm.playfield[colIdx] = 0
-				}
+		srcRowHeaderPos := (rowIdx + clearLineCount) * gm.width
+		for colIdx := 0; colIdx < gm.width; colIdx++ {
+			tile := emptyTile
+			if srcRowHeaderPos < len(gm.playfield) && gm.playfield[srcRowHeaderPos+colIdx] > 0 {
+				tile = gm.playfield[srcRowHeaderPos+colIdx] // marks are cleaned
 			}
+			gm.playfield[rowIdx*gm.width+colIdx] = tile
 		}
 	}
 	// GameManager Statistics
 	actionTotal := 0
-	lineOnlyRatio := []int{0, 100, 300, 500, 800}
-	miniTSpinRatio := []int{100, 200}
-	tSpinRatio := []int{400, 800, 1200, 1600}
-
-	if !gm.tSpinFlag {
-		actionTotal = gm.level * lineOnlyRatio[clearLineCount]
-	} else if gm.miniSpinFlag {
-		actionTotal = gm.level * miniTSpinRatio[clearLineCount]
-	} else {
-		actionTotal = gm.level * tSpinRatio[clearLineCount]
+	if clearType := gm.clearType(clearLineCount); clearType >= 0 {
+		actionTotal = gm.level * clearScores[clearType]
 	}
-
-	if gm.backToBackFlag {
+	// Tetris and T-Spins with line clears are difficult clears
+	difficultFlag := clearLineCount == 4 || (gm.tSpinFlag && clearLineCount > 0)
+	if gm.backToBackFlag && difficultFlag {
 		actionTotal = actionTotal + actionTotal/2
 	}
 
@@ -585,11 +722,17 @@ func (gm *GameManager) elimatePhase() {
 	if clearLineCount == 4 {
 		gm.tetrisCount++
 	}
+	if gm.tSpinFlag && clearLineCount > 0 {
+		gm.tSpinCount++
+	}
+	gm.lastClearLines = clearLineCount
+	gm.clearedLines += clearLineCount
+	gm.recordClear(clearLineCount)
 
 	// Reset Droplines
 	gm.softDropLine, gm.hardDropLine = 0, 0
-	// Reset Back-to-Back flag
-	if clearLineCount == 4 || gm.tSpinFlag {
+	// Reset Back-to-Back flag, T-Spins without line clears keep it
+	if difficultFlag {
 		gm.backToBackFlag = true
 	} else if clearLineCount > 0 {
 		gm.backToBackFlag = false
@@ -600,11 +743,19 @@ func (gm *GameManager) elimatePhase() {
 func (gm *GameManager) completionPhase() {
 	// update information
 	// level up condition
+	if gm.marathon != nil {
+		gm.checkLevelUp()
+	}
+	// puzzle goal condition
+	if gm.puzzle != nil && gm.puzzle.checkGoal(gm) {
+		gm.puzzleSolvedFlag = true
+		gm.endFlag = true
+	}
 }
 
 // Tetris engine flowchart
 func (gm *GameManager) loopFlow() {
-	for gm.generationPhase() {
+	for !gm.endFlag && gm.generationPhase() {
 		for {
 			gm.fallingPhase()
 			if !gm.lockPhase() {
@@ -629,13 +780,33 @@ func (gm *GameManager) processInput() {
 	case input := <-gm.inputCh:
 		switch input {
 		case moveLeft, moveRight:
+			gm.finesseInputs++
 			gm.move(input)
-		case rotateClockwise, rotateCounterClockwise:
+		case rotateClockwise, rotateCounterClockwise, rotate180:
+			gm.finesseInputs++
 			gm.rotate(input)
 		case softDrop:
+			gm.finesseSkipFlag = true
 			gm.softDrop()
 		case hardDrop:
 			gm.hardDrop()
+		case retry:
+			gm.retryFlag = true
+			gm.endFlag = true
+		case pause:
+			gm.pauseMenu()
+		case undoLock:
+			if gm.practiceFlag {
+				gm.undo()
+			}
+		case toggleGravity:
+			if gm.practiceFlag {
+				gm.gravityOffFlag = !gm.gravityOffFlag
+			}
+		default:
+			if gm.practiceFlag && input >= chooseNext && input < chooseNext+len(tetriminoShapes) {
+				gm.chooseNext(input - chooseNext)
+			}
 		}
 	default:
 
@@ -644,37 +815,112 @@ func (gm *GameManager) processInput() {
 
 }
 
+// block until resume, restart or quit is chosen
+func (gm *GameManager) pauseMenu() {
+	gm.pausedFlag, gm.pauseStartTime = true, time.Now()
+	gm.pauseMenuIdx = pauseMenuResume
+	defer func() {
+		gm.pausedDuration += time.Since(gm.pauseStartTime)
+		gm.pausedFlag = false
+	}()
+	for {
+		gm.renderOutput()
+		switch <-gm.inputCh {
+		case pause:
+			return
+		case rotateClockwise:
+			gm.pauseMenuIdx = (gm.pauseMenuIdx + pauseMenuItemNum - 1) % pauseMenuItemNum
+		case softDrop:
+			gm.pauseMenuIdx = (gm.pauseMenuIdx + 1) % pauseMenuItemNum
+		case hardDrop:
+			switch gm.pauseMenuIdx {
+			case pauseMenuRestart:
+				gm.retryFlag = true
+				gm.endFlag = true
+			case pauseMenuQuit:
+				gm.quitFlag = true
+				gm.endFlag = true
+			}
+			return
+		}
+	}
+}
+
 func (gm *GameManager) renderOutput() {
-	// Middle Panel
-	playfield := make([]int, gm.width*gm.height)
-	for i := 0; i < len(playfield); i++ {
-		playfield[i] = gm.playfield[i]
+	gm.renderer.Render(gm.frame())
+}
+
+// snapshot of current game for renderer
+func (gm *GameManager) frame() Frame {
+	visibleHeight := gm.height + gm.visibleBufferHeight
+	frame := Frame{
+		Playfield:     make([]int, gm.width*visibleHeight),
+		Width:         gm.width,
+		Height:        visibleHeight,
+		Skyline:       gm.height,
+		Scale:         gm.scale,
+		Next:          make([]int, tetriNum*tetriNum),
+		Hold:          make([]int, tetriNum*tetriNum),
+		Score:         gm.score,
+		HighScore:     gm.highScore,
+		Level:         gm.level,
+		TSpinCount:    gm.tSpinCount,
+		TetrisCount:   gm.tetrisCount,
+		ComboCount:    gm.comboCount,
+		FinesseFaults: gm.finesseFaults,
+		Stats:         gm.Statistics(),
+		Paused:        gm.pausedFlag,
+		PauseMenuIdx:  gm.pauseMenuIdx,
+		Practice:      gm.practiceFlag,
+		GravityOff:    gm.gravityOffFlag,
+	}
+	if gm.marathon != nil {
+		frame.Goal = gm.marathon.Goal - gm.levelLines
+	}
+	if len(gm.history) > 1 {
+		frame.Undos = len(gm.history) - 1
+	}
+	// Middle Panel (hidden while paused)
+	if gm.pausedFlag {
+		return frame
+	}
+	copy(frame.Playfield, gm.playfield)
+	for i, tile := range frame.Playfield {
+		if tile > 0 {
+			frame.StackTop = i/gm.width + 1
+		}
 	}
-	for i := tetriminoShapes[gm.tetriminoIdx][gm.tetriminoDrct]; i != 0; i >>= tetriNum {
-		x, y := gm.calcGhostMinoPosOnBoard(i)
-		if x+y*gm.width < len(playfield) {
-			playfield[x+y*gm.width] = (gm.tetriminoIdx + 1) * -1
+	// where the next tetrimino spawns stands in for a missing one
+	frame.PieceTop = gm.tetriminoSpawnY + 1
+	if gm.activeFlag {
+		frame.PieceTop = 0
+		for i := tetriminoShapes[gm.tetriminoIdx][gm.tetriminoDrct]; i != 0; i >>= tetriNum {
+			x, y := gm.calcGhostMinoPosOnBoard(i)
+			if gm.allowGhost && y >= 0 && y < visibleHeight {
+				frame.Playfield[x+y*gm.width] = (gm.tetriminoIdx + 1) * -1
+			}
+			x, y = gm.calcMinoPosOnBoard(i)
+			if y+1 > frame.PieceTop {
+				frame.PieceTop = y + 1
+			}
+			if y >= 0 && y < visibleHeight {
+				frame.Playfield[x+y*gm.width] = gm.tetriminoIdx + 1
+			}
 		}
-		x, y = gm.calcMinoPosOnBoard(i)
-		if x+y*gm.width < len(playfield) {
-			playfield[x+y*gm.width] = gm.tetriminoIdx + 1
+	}
+	// Right Panel
+	if gm.nextTetriminoIdx >= 0 {
+		for i := tetriminoShapes[gm.nextTetriminoIdx][0]; i != 0; i >>= tetriNum {
+			frame.Next[i&15] = gm.nextTetriminoIdx + 1
 		}
 	}
-	nextTetrimino := make([][]int, 1)
-	nextTetrimino[0] = make([]int, tetriNum*tetriNum)
-	for i := tetriminoShapes[gm.nextTetriminoIdx][0]; i != 0; i >>= tetriNum {
-
-		nextTetrimino[0][i&15] = gm.nextTetriminoIdx + 1
+	if len(gm.stashQueue) > 0 && gm.stashQueue[0] > 0 {
+		holdIdx := gm.stashQueue[0] - 1
+		for i := tetriminoShapes[holdIdx][0]; i != 0; i >>= tetriNum {
+			frame.Hold[i&15] = gm.stashQueue[0]
+		}
 	}
-	// Left panel
-	//   score, time, lines, level, goal, tetrises, tspins, combos, TPM, LPM
-	// Right Panel
-
-	gm.renderer(
-		playfield, nextTetrimino[0], gm.height, gm.width,
-		gm.score, gm.highScore, gm.level,
-		gm.tSpinCount, gm.tetrisCount, gm.comboCount,
-	)
+	return frame
 }
 
 // ============= Export Function ===============
@@ -684,21 +930,47 @@ func (gm *GameManager) LoadHighScore(highScore int) {
 	gm.highScore = highScore
 }
 
-// GetSetups of game manager
-func (gm *GameManager) GetSetups() {}
-
-// Setup game optional settings
-func (gm *GameManager) Setup() {}
-
-// RestoreDefaultSetup for game manager
-func (gm *GameManager) RestoreDefaultSetup() {}
-
-// NewGame start for caller
+// NewGame start for caller, and start over on restart.
+// It returns once the game is over or quit is chosen in pause menu.
 // GameManager Over condition occurs in Generation Phase and Lock Phase
 func (gm *GameManager) NewGame() {
-	gm.reload()
-	gm.startTime = time.Now()
+	for {
+		gm.reload()
+		gm.Continue()
+		// GameManager Over Events
+		if !gm.retryFlag {
+			return
+		}
+	}
+}
+
+// Continue game from current state (e.g. a position loaded by ImportFumen)
+func (gm *GameManager) Continue() {
+	if gm.playfield == nil {
+		gm.reload()
+	}
+	gm.startTime = time.Now().Add(-gm.resumedDuration)
 	// Tetris engine flowchart
 	gm.loopFlow()
-	// GameManager Over Events
+}
+
+// Seed the random generator of bag system, identical seeds deal identical
+// sequences from next game on
+func (gm *GameManager) Seed(seed int64) {
+	if gm.rngSource == nil {
+		gm.rngSource = rng.NewSource(seed)
+		gm.rng = rng.New(gm.rngSource)
+		return
+	}
+	gm.rngSource.Seed(seed)
+}
+
+// Score of current game
+func (gm *GameManager) Score() int {
+	return gm.score
+}
+
+// QuitRequested reports whether player chose quit from pause menu
+func (gm *GameManager) QuitRequested() bool {
+	return gm.quitFlag
 }
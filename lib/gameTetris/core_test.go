@@ -2,6 +2,7 @@ package gameTetris
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,36 +12,246 @@ type testCase struct {
 
 var gm *GameManager
 
-// func TestNewGameManager(t *testing.T) {
-// 	gm = NewGameManager()
-// 	assert.NotNil(t, gm, "Failed to instantiate class(GameManager)")
-// }
+func newTestGameManager() *GameManager {
+	return NewGameManager(make(chan int), NopRenderer{})
+}
+
+func TestNewGameManager(t *testing.T) {
+	gm = newTestGameManager()
+	assert.NotNil(t, gm, "Failed to instantiate class(GameManager)")
+}
 
 func TestGameManager_reload(t *testing.T) {
 	gm.reload()
 }
 
 func TestGame_checkBorderX(t *testing.T) {
-	posTestCases := []int{-10, -5, -2, -1}
+	posTestCases := []int{0, 1, 5, 9}
 	for _, testCase := range posTestCases {
 		assert.True(t, gm.checkBorderX(testCase), "error in positive testcase when check border X")
 	}
-	negTestCases := []int{0, 5, 10, 20, 30}
+	negTestCases := []int{-10, -5, -1, 10, 20, 30}
 	for _, testCase := range negTestCases {
 		assert.False(t, gm.checkBorderX(testCase), " error in negative testcase when check border X")
 	}
 }
 
 func TestGame_checkBorderY(t *testing.T) {
-	posTestCases := []int{-10, -5, -2, -1}
+	posTestCases := []int{0, 5, 10, 20, 39}
 	for _, testCase := range posTestCases {
-		assert.True(t, gm.checkBorderX(testCase), "error in positive testcase when check border Y")
+		assert.True(t, gm.checkBorderY(testCase), "error in positive testcase when check border Y")
 	}
-	negTestCases := []int{0, 5, 10, 20, 30}
+	negTestCases := []int{-10, -5, -2, -1, 40}
 	for _, testCase := range negTestCases {
-		assert.False(t, gm.checkBorderX(testCase), " error in negative testcase when check border Y")
+		assert.False(t, gm.checkBorderY(testCase), " error in negative testcase when check border Y")
 	}
 }
 
 func TestGame_calcPosOnBoard(t *testing.T) {
 }
+
+func TestGameManager_pauseMenu(t *testing.T) {
+	inputCh := make(chan int, 10)
+	for _, op := range []int{
+		pause, softDrop, hardDrop, // restart
+		pause, rotateClockwise, hardDrop, // quit
+	} {
+		inputCh <- op
+	}
+	g := newTestGameManager()
+	g.inputCh = inputCh
+	g.NewGame()
+	assert.True(t, g.QuitRequested())
+	assert.False(t, g.pausedFlag)
+	assert.Len(t, inputCh, 0)
+}
+
+func TestGameManager_now(t *testing.T) {
+	g := newTestGameManager()
+	g.pausedFlag, g.pauseStartTime = true, time.Now().Add(-time.Second)
+	g.pausedDuration = time.Minute
+	assert.Equal(t, g.pauseStartTime.Add(-time.Minute), g.now(), "clock stands still while paused")
+}
+
+func TestGameManager_rotate180(t *testing.T) {
+	for _, kicks := range []int{Rotate180SRSPlus, Rotate180TetrisOnline} {
+		g := newTestGameManager()
+		g.rotate180Kicks = kicks
+		g.reload()
+		g.tetriminoIdx, g.tetriminoDrct = tetriminoShapeT, 0
+		g.tetriminoX, g.tetriminoY = 3, 5
+		g.rotate(rotate180)
+		assert.Equal(t, 2, g.tetriminoDrct)
+		assert.Equal(t, 3, g.tetriminoX)
+		assert.Equal(t, 5, g.tetriminoY)
+		assert.Equal(t, rotate180, g.lastOp)
+
+		// resting on the floor, the flipped nub has to kick up
+		g.tetriminoDrct, g.tetriminoY = 0, 1
+		g.rotate(rotate180)
+		assert.Equal(t, 2, g.tetriminoDrct, "kicks %d", kicks)
+		assert.Equal(t, 3, g.tetriminoX, "kicks %d", kicks)
+		assert.Equal(t, 2, g.tetriminoY, "kicks %d", kicks)
+	}
+
+	g := newTestGameManager()
+	g.allowRotate180 = false
+	g.reload()
+	g.tetriminoIdx, g.tetriminoDrct = tetriminoShapeT, 0
+	g.tetriminoX, g.tetriminoY = 3, 5
+	g.rotate(rotate180)
+	assert.Equal(t, 0, g.tetriminoDrct, "180 spin disabled")
+}
+
+// drop T from above the board through ops, lock it down and clear lines
+func playTSpin(t *testing.T, rows []string, ops ...int) *GameManager {
+	g := newTestGameManager()
+	g.reload()
+	assert.NoError(t, g.loadPuzzle(&Puzzle{rows: rows, pieces: []int{tetriminoShapeT}}))
+	g.tetriminoIdx, g.tetriminoDrct = tetriminoShapeT, 0
+	g.tetriminoX, g.tetriminoY = g.tetriminoSpawnX, 7
+	for _, op := range ops {
+		g.moveFlag = false
+		switch op {
+		case softDrop:
+			g.checkLanding()
+			assert.False(t, g.landFlag)
+			g.tetriminoY--
+			g.lastOp = softDrop
+			continue
+		case moveLeft, moveRight:
+			g.move(op)
+		default:
+			g.rotate(op)
+		}
+		assert.True(t, g.moveFlag, "op %d blocked", op)
+	}
+	g.calcGhostPos()
+	g.hardDrop() // already landed, the spin stays
+	g.lockDown()
+	g.checkTSpin()
+	g.patternPhase()
+	g.elimatePhase()
+	return g
+}
+
+func TestGameManager_checkTSpin(t *testing.T) {
+	tsd := []string{
+		"XXX.......",
+		"XX...XXXXX",
+		"XXX.XXXXXX",
+	}
+	g := playTSpin(t, tsd, moveLeft, softDrop, softDrop, softDrop, rotateClockwise,
+		softDrop, softDrop, rotateClockwise)
+	assert.True(t, g.tSpinFlag)
+	assert.False(t, g.miniSpinFlag)
+	assert.Equal(t, 2, g.lastClearLines)
+	assert.Equal(t, 1200, g.score)
+	assert.Equal(t, 1, g.tSpinCount)
+	assert.Equal(t, 1, g.clearCounts[clearTSpinDouble])
+	assert.True(t, g.backToBackFlag)
+
+	// pointing up into the slot, the front corners are open
+	g = playTSpin(t, tsd, moveLeft, softDrop, softDrop, softDrop, rotateClockwise,
+		softDrop, softDrop, rotateCounterClockwise)
+	assert.True(t, g.tSpinFlag)
+	assert.True(t, g.miniSpinFlag)
+	assert.Equal(t, 1, g.lastClearLines)
+	assert.Equal(t, 200, g.score)
+	assert.Equal(t, 1, g.clearCounts[clearMiniTSpinSingle])
+
+	tst := []string{
+		"XXXX......",
+		"XXX.......",
+		"XXX.XXXXXX",
+		"XXX..XXXXX",
+		"XXX.XXXXXX",
+	}
+	g = playTSpin(t, tst, moveRight, softDrop, softDrop, softDrop, moveLeft, rotateClockwise)
+	assert.Equal(t, kickWallTableTestCaseNum-1, g.lastKick)
+	assert.True(t, g.tSpinFlag)
+	assert.False(t, g.miniSpinFlag)
+	assert.Equal(t, 3, g.lastClearLines)
+	assert.Equal(t, 1600, g.score)
+	assert.Equal(t, 1, g.clearCounts[clearTSpinTriple])
+
+	// only one front corner is occupied, the TST kick upgrades the mini
+	upgrade := []string{
+		"...X......",
+		"X.......XX",
+		"XXX.XXXXXX",
+		"XXX..XXXXX",
+		"XXX..XXXXX",
+	}
+	g = playTSpin(t, upgrade, moveRight, softDrop, softDrop, softDrop, moveLeft, rotateClockwise)
+	assert.True(t, g.tSpinFlag)
+	assert.False(t, g.miniSpinFlag)
+	assert.Equal(t, 2, g.lastClearLines)
+
+	g = newTestGameManager()
+	g.reload()
+	assert.NoError(t, g.loadPuzzle(&Puzzle{rows: upgrade, pieces: []int{tetriminoShapeT}}))
+	g.tetriminoIdx, g.tetriminoDrct = tetriminoShapeT, 1
+	g.tetriminoX, g.tetriminoY = 2, 2
+	g.lastOp, g.lastKick = rotateClockwise, 0
+	g.lockDown()
+	g.checkTSpin()
+	assert.True(t, g.tSpinFlag)
+	assert.True(t, g.miniSpinFlag, "mini without the TST kick")
+}
+
+func TestGameManager_elimatePhaseBackToBack(t *testing.T) {
+	g := newTestGameManager()
+	g.reload()
+	g.backToBackFlag, g.tSpinFlag = true, true
+	g.elimatePhase()
+	assert.Equal(t, 400, g.score, "T-Spin without lines gets no back-to-back bonus")
+	assert.True(t, g.backToBackFlag, "and keeps the chain")
+	assert.Equal(t, 0, g.tSpinCount)
+	assert.Equal(t, 1, g.clearCounts[clearTSpin])
+
+	g.tSpinFlag = false
+	for i := 0; i < g.width; i++ {
+		g.playfield[i] = rowFull
+	}
+	g.elimatePhase()
+	assert.Equal(t, 500, g.score)
+	assert.False(t, g.backToBackFlag, "a single breaks the chain")
+}
+
+// full rows apart from each other are all cleared, the rows between fall
+func TestGameManager_elimatePhaseRowsApart(t *testing.T) {
+	g := newTestGameManager()
+	assert.NoError(t, g.loadPuzzle(&Puzzle{rows: []string{
+		"XXXXXXXXXX",
+		"X.X.......",
+		"XXXXXXXXXX",
+		"..XX......",
+		"XXXXXXXXXX",
+	}}))
+	g.patternPhase()
+	assert.True(t, g.patternMatchFlag)
+	g.elimatePhase()
+	assert.Equal(t, 3, g.lastClearLines)
+
+	want := make([]int, len(g.playfield))
+	copy(want, []int{
+		0, 0, garbageTile, garbageTile, 0, 0, 0, 0, 0, 0,
+		garbageTile, 0, garbageTile, 0, 0, 0, 0, 0, 0, 0,
+	})
+	assert.Equal(t, want, g.playfield)
+}
+
+func TestGameManager_checkTSpinAfterRotate180(t *testing.T) {
+	g := newTestGameManager()
+	g.reload()
+	// three corners around center (4, 1) of a reversed T
+	g.playfield[3+2*g.width] = garbageTile
+	g.playfield[3] = garbageTile
+	g.playfield[5] = garbageTile
+	g.tetriminoIdx, g.tetriminoDrct = tetriminoShapeT, 2
+	g.tetriminoX, g.tetriminoY = 3, 2
+	g.lastOp = rotate180
+	g.checkTSpin()
+	assert.True(t, g.tSpinFlag)
+}
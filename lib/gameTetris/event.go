@@ -0,0 +1,33 @@
+package gameTetris
+
+// event type
+const (
+	EventFinesseFault = iota
+)
+
+// Event is something notable happened in game, published for training
+// tools and other observers
+type Event struct {
+	Type int
+	// tetrimino name ("OITLJSZ") and its final column and facing
+	Tetrimino string
+	X, Facing int
+	// inputs used for the placement and the fewest possible
+	Inputs, OptimalInputs int
+}
+
+// SetEventChannel let game publish events into ch. Events are dropped while
+// ch is full, so a slow reader never stalls the game.
+func (gm *GameManager) SetEventChannel(ch chan<- Event) {
+	gm.eventCh = ch
+}
+
+func (gm *GameManager) emitEvent(ev Event) {
+	if gm.eventCh == nil {
+		return
+	}
+	select {
+	case gm.eventCh <- ev:
+	default:
+	}
+}
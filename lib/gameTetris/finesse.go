@@ -0,0 +1,123 @@
+package gameTetris
+
+// Finesse is placing a tetrimino with the fewest inputs. Placements are
+// judged against a search on an empty playfield from the spawn position, so
+// only straight drops count: soft drops, tucks and spins are not judged.
+
+type finesseState struct {
+	x, drct int
+}
+
+// minos of a placement, shifted so the lowest mino sits on row 0 and
+// sorted, so facings with the same footprint compare equal
+func placementKey(idx, x, drct int) [tetriNum][2]int {
+	minos := tetriminoMinos(idx, drct)
+	minY := minos[0][1]
+	for _, mino := range minos {
+		if mino[1] < minY {
+			minY = mino[1]
+		}
+	}
+	for i := range minos {
+		minos[i][0] += x
+		minos[i][1] -= minY
+	}
+	for i := 1; i < len(minos); i++ {
+		for j := i; j > 0 && (minos[j][1] < minos[j-1][1] ||
+			minos[j][1] == minos[j-1][1] && minos[j][0] < minos[j-1][0]); j-- {
+			minos[j], minos[j-1] = minos[j-1], minos[j]
+		}
+	}
+	return minos
+}
+
+// whether all minos stay between the walls
+func (gm *GameManager) finesseFits(x, drct int) bool {
+	for i := tetriminoShapes[gm.tetriminoIdx][drct]; i != 0; i >>= tetriNum {
+		if !gm.checkBorderX(x + i%tetriNum) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply operation on an empty playfield, kicks only shift horizontally
+func (gm *GameManager) finesseStep(s finesseState, opCode int) (finesseState, bool) {
+	switch opCode {
+	case moveLeft, moveRight:
+		if opCode == moveLeft {
+			s.x--
+		} else {
+			s.x++
+		}
+		return s, gm.finesseFits(s.x, s.drct)
+	}
+	if opCode == rotate180 && !gm.allowRotate180 {
+		return s, false
+	}
+	dstDrct := gm.rotatedDrct(s.drct, opCode)
+	for _, testCase := range gm.kickTests(s.drct, opCode) {
+		x := s.x + testCase[0]
+		if gm.finesseFits(x, dstDrct) {
+			return finesseState{x, dstDrct}, true
+		}
+	}
+	return s, false
+}
+
+// the fewest move and rotate inputs from spawn to current placement
+func (gm *GameManager) calcOptimalInputs() int {
+	target := placementKey(gm.tetriminoIdx, gm.tetriminoX, gm.tetriminoDrct)
+	start := finesseState{gm.tetriminoSpawnX, 0}
+	dist := map[finesseState]int{start: 0}
+	queue := []finesseState{start}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if placementKey(gm.tetriminoIdx, s.x, s.drct) == target {
+			return dist[s]
+		}
+		for _, opCode := range []int{moveLeft, moveRight, rotateClockwise, rotateCounterClockwise, rotate180} {
+			next, ok := gm.finesseStep(s, opCode)
+			if _, seen := dist[next]; ok && !seen {
+				dist[next] = dist[s] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return -1
+}
+
+// whether current tetrimino could have been dropped straight from spawn height
+func (gm *GameManager) checkStraightDrop() bool {
+	for y := gm.tetriminoY; y <= gm.tetriminoSpawnY; y++ {
+		for i := tetriminoShapes[gm.tetriminoIdx][gm.tetriminoDrct]; i != 0; i >>= tetriNum {
+			x, minoY := gm.calcMinoPosOnBoard(i)
+			minoY += y - gm.tetriminoY
+			if gm.checkBorderY(minoY) && gm.playfield[x+minoY*gm.width] != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// judge the placement of current tetrimino before it locks down
+func (gm *GameManager) checkFinesse() {
+	if gm.finesseSkipFlag || !gm.checkStraightDrop() {
+		return
+	}
+	optimal := gm.calcOptimalInputs()
+	if optimal < 0 || gm.finesseInputs <= optimal {
+		return
+	}
+	gm.finesseFaults++
+	gm.emitEvent(Event{
+		Type:          EventFinesseFault,
+		Tetrimino:     string(tetriminoNames[gm.tetriminoIdx]),
+		X:             gm.tetriminoX,
+		Facing:        gm.tetriminoDrct,
+		Inputs:        gm.finesseInputs,
+		OptimalInputs: optimal,
+	})
+}
@@ -0,0 +1,58 @@
+package gameTetris
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameManager_calcOptimalInputs(t *testing.T) {
+	g := newTestGameManager()
+	g.reload()
+	testCases := []struct {
+		idx, x, drct, optimal int
+	}{
+		{tetriminoShapeT, 3, 0, 0},
+		{tetriminoShapeT, 0, 0, 3},
+		{tetriminoShapeT, 3, 2, 1}, // one 180 spin
+		{tetriminoShapeO, 7, 0, 4},
+		{tetriminoShapeO, 7, 3, 4},  // O looks the same in every facing
+		{tetriminoShapeI, -1, 3, 5}, // upright in the leftmost column
+		{tetriminoShapeI, -2, 1, 5}, // the same placement, other facing
+	}
+	for _, testCase := range testCases {
+		g.tetriminoIdx, g.tetriminoX, g.tetriminoDrct = testCase.idx, testCase.x, testCase.drct
+		assert.Equal(t, testCase.optimal, g.calcOptimalInputs(), "%+v", testCase)
+	}
+
+	g.allowRotate180 = false
+	g.tetriminoIdx, g.tetriminoX, g.tetriminoDrct = tetriminoShapeT, 3, 2
+	assert.Equal(t, 2, g.calcOptimalInputs(), "two rotations without 180 spin")
+}
+
+func TestGameManager_checkFinesse(t *testing.T) {
+	p, err := parsePuzzle(strings.NewReader("goal: lines 4\npieces: TT"))
+	assert.NoError(t, err)
+
+	inputCh := make(chan int, 10)
+	for _, op := range []int{
+		moveLeft, moveRight, hardDrop, // wasted two inputs
+		moveLeft, hardDrop, // optimal
+	} {
+		inputCh <- op
+	}
+	eventCh := make(chan Event, 10)
+	g := newTestGameManager()
+	g.inputCh = inputCh
+	g.SetEventChannel(eventCh)
+	_, err = g.PlayPuzzle(p)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, g.finesseFaults)
+	assert.Len(t, eventCh, 1)
+	ev := <-eventCh
+	assert.Equal(t, EventFinesseFault, ev.Type)
+	assert.Equal(t, "T", ev.Tetrimino)
+	assert.Equal(t, 2, ev.Inputs)
+	assert.Equal(t, 0, ev.OptimalInputs)
+}
@@ -0,0 +1,526 @@
+package gameTetris
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Fumen (v115) is the board encoding used by the Tetris community to share
+// positions. A fumen is a list of pages, each holding a 10x23 field (plus a
+// garbage row), an operation piece and an optional comment. Only the first
+// page is read or written here; hold, current and next pieces are carried in
+// the "#Q=[hold](current)next" quiz comment.
+const (
+	fumenPrefix        = "v115@"
+	fumenWidth         = 10
+	fumenFieldTop      = 23
+	fumenFieldBlocks   = (fumenFieldTop + 1) * fumenWidth
+	fumenTable         = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	fumenCommentTable  = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+	fumenCommentMaxLen = 4095
+	fumenQuizPrefix    = "#Q="
+)
+
+// fumen piece: 0 empty, 1 I, 2 L, 3 O, 4 Z, 5 T, 6 J, 7 S, 8 gray
+const (
+	fumenPieceEmpty = 0
+	fumenPieceGray  = 8
+)
+
+// fumen piece indexed by tetrimino shape
+var fumenPieces = [7]int{3, 1, 5, 2, 6, 7, 4}
+
+// fumen rotation (0 reverse, 1 right, 2 spawn, 3 left) indexed by facing
+var fumenRotations = [4]int{2, 1, 0, 3}
+
+// fumen mino offsets from the piece center at spawn rotation (y axis up)
+var fumenPieceMinos = [9][tetriNum][2]int{
+	1: {{0, 0}, {-1, 0}, {1, 0}, {2, 0}},  // I
+	2: {{0, 0}, {-1, 0}, {1, 0}, {1, 1}},  // L
+	3: {{0, 0}, {1, 0}, {0, 1}, {1, 1}},   // O
+	4: {{0, 0}, {1, 0}, {0, 1}, {-1, 1}},  // Z
+	5: {{0, 0}, {-1, 0}, {1, 0}, {0, 1}},  // T
+	6: {{0, 0}, {-1, 0}, {1, 0}, {-1, 1}}, // J
+	7: {{0, 0}, {-1, 0}, {0, 1}, {1, 1}},  // S
+}
+
+// shape names indexed by tetrimino shape, as written in quiz comments
+const tetriminoNames = "OITLJSZ"
+
+var fumenQuizPattern = regexp.MustCompile(`^#Q=\[([IOTLJSZ]?)\]\(([IOTLJSZ]?)\)([IOTLJSZ]*)`)
+
+type fumenPage struct {
+	field                  [fumenFieldBlocks]int
+	piece, rotation, x, y  int
+	rise, mirror, colorize bool
+	lock                   bool
+	comment                string
+}
+
+// ================ Codec ====================
+
+type fumenBuffer struct {
+	values []int
+	pos    int
+}
+
+// push value as count little-endian digits of base 64
+func (b *fumenBuffer) push(value, count int) {
+	for i := 0; i < count; i++ {
+		b.values = append(b.values, value%len(fumenTable))
+		value /= len(fumenTable)
+	}
+}
+
+func (b *fumenBuffer) poll(count int) (int, error) {
+	if b.pos+count > len(b.values) {
+		return 0, errors.New("unexpected end of fumen data")
+	}
+	value, base := 0, 1
+	for i := 0; i < count; i++ {
+		value += b.values[b.pos] * base
+		base *= len(fumenTable)
+		b.pos++
+	}
+	return value, nil
+}
+
+func (b *fumenBuffer) String() string {
+	var sb strings.Builder
+	for _, v := range b.values {
+		sb.WriteByte(fumenTable[v])
+	}
+	// fumen breaks long data with '?' to keep it linkable
+	data := sb.String()
+	if len(data) <= 42 {
+		return data
+	}
+	chunks := []string{data[:42]}
+	for i := 42; i < len(data); i += 47 {
+		end := i + 47
+		if end > len(data) {
+			end = len(data)
+		}
+		chunks = append(chunks, data[i:end])
+	}
+	return strings.Join(chunks, "?")
+}
+
+func encodeFumen(page *fumenPage) string {
+	buf := &fumenBuffer{}
+
+	// field: run length of (diff from empty field + 8)
+	changed := false
+	prevDiff, count := page.field[0]+8, -1
+	for _, block := range page.field {
+		if diff := block + 8; diff != prevDiff {
+			buf.push(prevDiff*fumenFieldBlocks+count, 2)
+			prevDiff, count, changed = diff, 0, true
+		} else {
+			count++
+		}
+	}
+	buf.push(prevDiff*fumenFieldBlocks+count, 2)
+	if !changed {
+		buf.push(0, 1) // no repeated empty fields follow
+	}
+
+	// action
+	value := boolToInt(!page.lock)
+	value = value*2 + boolToInt(page.comment != "")
+	value = value*2 + boolToInt(page.colorize)
+	value = value*2 + boolToInt(page.mirror)
+	value = value*2 + boolToInt(page.rise)
+	value = value*fumenFieldBlocks + encodeFumenPosition(page)
+	value = value*4 + page.rotation
+	value = value*8 + page.piece
+	buf.push(value, 3)
+
+	// comment: 4 characters per 5 digits
+	if page.comment != "" {
+		comment := fumenEscape(page.comment)
+		if len(comment) > fumenCommentMaxLen {
+			comment = comment[:fumenCommentMaxLen]
+		}
+		buf.push(len(comment), 2)
+		for i := 0; i < len(comment); i += 4 {
+			value, base := 0, 1
+			for j := i; j < i+4 && j < len(comment); j++ {
+				value += strings.IndexByte(fumenCommentTable, comment[j]) * base
+				base *= len(fumenCommentTable) + 1
+			}
+			buf.push(value, 5)
+		}
+	}
+
+	return fumenPrefix + buf.String()
+}
+
+func decodeFumen(data string) (*fumenPage, error) {
+	at := strings.Index(data, "115@")
+	if at < 0 {
+		return nil, errors.New("unsupported fumen version")
+	}
+	data = strings.NewReplacer("?", "", " ", "", "\n", "").Replace(data[at+4:])
+	buf := &fumenBuffer{}
+	for i := 0; i < len(data); i++ {
+		v := strings.IndexByte(fumenTable, data[i])
+		if v < 0 {
+			return nil, fmt.Errorf("invalid fumen character %q", data[i])
+		}
+		buf.values = append(buf.values, v)
+	}
+
+	page := &fumenPage{}
+	for idx := 0; idx < fumenFieldBlocks; {
+		value, err := buf.poll(2)
+		if err != nil {
+			return nil, err
+		}
+		diff, count := value/fumenFieldBlocks, value%fumenFieldBlocks+1
+		if diff == 8 && count == fumenFieldBlocks {
+			if _, err := buf.poll(1); err != nil { // repeat count of empty fields
+				return nil, err
+			}
+		}
+		if idx+count > fumenFieldBlocks {
+			return nil, errors.New("fumen field overflow")
+		}
+		for ; count > 0; count-- {
+			page.field[idx] = diff - 8
+			idx++
+		}
+	}
+	for _, block := range page.field {
+		if block < fumenPieceEmpty || block > fumenPieceGray {
+			return nil, errors.New("invalid fumen block")
+		}
+	}
+
+	value, err := buf.poll(3)
+	if err != nil {
+		return nil, err
+	}
+	page.piece, value = value%8, value/8
+	page.rotation, value = value%4, value/4
+	pos, value := value%fumenFieldBlocks, value/fumenFieldBlocks
+	page.rise, value = value%2 != 0, value/2
+	page.mirror, value = value%2 != 0, value/2
+	page.colorize, value = value%2 != 0, value/2
+	hasComment, value := value%2 != 0, value/2
+	page.lock = value%2 == 0
+	decodeFumenPosition(page, pos)
+
+	if hasComment {
+		length, err := buf.poll(2)
+		if err != nil {
+			return nil, err
+		}
+		var sb strings.Builder
+		for i := 0; i < length; i += 4 {
+			value, err := buf.poll(5)
+			if err != nil {
+				return nil, err
+			}
+			for j := i; j < i+4 && j < length; j++ {
+				c := value % (len(fumenCommentTable) + 1)
+				if c >= len(fumenCommentTable) {
+					return nil, errors.New("invalid fumen comment")
+				}
+				sb.WriteByte(fumenCommentTable[c])
+				value /= len(fumenCommentTable) + 1
+			}
+		}
+		if page.comment, err = fumenUnescape(sb.String()); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// fumen positions are not all piece centers, these pieces are shifted
+func fumenPositionShift(piece, rotation int) (dx, dy int) {
+	switch {
+	case piece == 3 && rotation == 3: // O left
+		return -1, 1
+	case piece == 3 && rotation == 0: // O reverse
+		return -1, 0
+	case piece == 3 && rotation == 2: // O spawn
+		return 0, 1
+	case piece == 1 && rotation == 0: // I reverse
+		return -1, 0
+	case piece == 1 && rotation == 3: // I left
+		return 0, 1
+	case piece == 7 && rotation == 2: // S spawn
+		return 0, 1
+	case piece == 7 && rotation == 1: // S right
+		return 1, 0
+	case piece == 4 && rotation == 2: // Z spawn
+		return 0, 1
+	case piece == 4 && rotation == 3: // Z left
+		return -1, 0
+	}
+	return 0, 0
+}
+
+func encodeFumenPosition(page *fumenPage) int {
+	if page.piece == fumenPieceEmpty || page.piece == fumenPieceGray {
+		return 0
+	}
+	dx, dy := fumenPositionShift(page.piece, page.rotation)
+	return (fumenFieldTop-(page.y+dy)-1)*fumenWidth + page.x + dx
+}
+
+func decodeFumenPosition(page *fumenPage, pos int) {
+	page.x, page.y = pos%fumenWidth, fumenFieldTop-pos/fumenWidth-1
+	if page.piece == fumenPieceEmpty || page.piece == fumenPieceGray {
+		return
+	}
+	dx, dy := fumenPositionShift(page.piece, page.rotation)
+	page.x, page.y = page.x-dx, page.y-dy
+}
+
+// minos of fumen piece around its center
+func fumenMinos(piece, rotation int) (minos [tetriNum][2]int) {
+	for i, mino := range fumenPieceMinos[piece] {
+		x, y := mino[0], mino[1]
+		switch rotation {
+		case 0: // reverse
+			x, y = -x, -y
+		case 1: // right
+			x, y = y, -x
+		case 3: // left
+			x, y = -y, x
+		}
+		minos[i] = [2]int{x, y}
+	}
+	return minos
+}
+
+// the lowest, then leftmost mino, used to align two sets of minos
+func fumenAnchor(minos [tetriNum][2]int) (x, y int) {
+	x, y = minos[0][0], minos[0][1]
+	for _, mino := range minos[1:] {
+		if mino[1] < y || (mino[1] == y && mino[0] < x) {
+			x, y = mino[0], mino[1]
+		}
+	}
+	return x, y
+}
+
+// minos of tetrimino relative to its position on board
+func tetriminoMinos(idx, drct int) (minos [tetriNum][2]int) {
+	j := 0
+	for i := tetriminoShapes[idx][drct]; i != 0; i >>= tetriNum {
+		minos[j] = [2]int{i % tetriNum, -(i / tetriNum % tetriNum)}
+		j++
+	}
+	return minos
+}
+
+// JavaScript escape(), which fumen applies to comments
+func fumenEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9',
+			strings.ContainsRune("@*_+-./", r):
+			sb.WriteRune(r)
+		case r < 0x100:
+			fmt.Fprintf(&sb, "%%%02X", r)
+		default:
+			fmt.Fprintf(&sb, "%%u%04X", r)
+		}
+	}
+	return sb.String()
+}
+
+// JavaScript unescape()
+func fumenUnescape(s string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		digits := 2
+		if i+1 < len(s) && s[i+1] == 'u' {
+			i++
+			digits = 4
+		}
+		if i+digits >= len(s) {
+			return "", errors.New("invalid escape in fumen comment")
+		}
+		r, err := strconv.ParseUint(s[i+1:i+1+digits], 16, 32)
+		if err != nil {
+			return "", errors.New("invalid escape in fumen comment")
+		}
+		sb.WriteRune(rune(r))
+		i += digits
+	}
+	return sb.String(), nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// ============= Export Function ===============
+
+// ExportFumen encode playfield, current tetrimino, hold and next queue
+// into a v115 fumen
+func (gm *GameManager) ExportFumen() (string, error) {
+	if gm.playfield == nil {
+		return "", errors.New("no game loaded")
+	}
+	if gm.width != fumenWidth {
+		return "", fmt.Errorf("fumen requires width %d, got %d", fumenWidth, gm.width)
+	}
+
+	page := &fumenPage{lock: true, colorize: true}
+	for i, tile := range gm.playfield {
+		if tile <= 0 {
+			continue
+		}
+		x, y := i%gm.width, i/gm.width
+		if y >= fumenFieldTop {
+			return "", fmt.Errorf("mino at row %d is above fumen field", y)
+		}
+		if tile > len(fumenPieces) {
+			page.field[(fumenFieldTop-y-1)*fumenWidth+x] = fumenPieceGray
+		} else {
+			page.field[(fumenFieldTop-y-1)*fumenWidth+x] = fumenPieces[tile-1]
+		}
+	}
+
+	var quiz strings.Builder
+	quiz.WriteString(fumenQuizPrefix + "[")
+	if len(gm.stashQueue) > 0 && gm.stashQueue[0] > 0 {
+		quiz.WriteByte(tetriminoNames[gm.stashQueue[0]-1])
+	}
+	quiz.WriteString("](")
+	upcoming := gm.upcomingTetriminos()
+	if gm.activeFlag {
+		page.piece = fumenPieces[gm.tetriminoIdx]
+		page.rotation = fumenRotations[gm.tetriminoDrct]
+		boardX, boardY := fumenAnchor(tetriminoMinos(gm.tetriminoIdx, gm.tetriminoDrct))
+		centerX, centerY := fumenAnchor(fumenMinos(page.piece, page.rotation))
+		page.x = gm.tetriminoX + boardX - centerX
+		page.y = gm.tetriminoY + boardY - centerY
+		if page.y < 0 || page.y >= fumenFieldTop {
+			return "", errors.New("current tetrimino is outside fumen field")
+		}
+		quiz.WriteByte(tetriminoNames[gm.tetriminoIdx])
+	} else if len(upcoming) > 0 {
+		quiz.WriteByte(tetriminoNames[upcoming[0]])
+		upcoming = upcoming[1:]
+	}
+	quiz.WriteString(")")
+	for _, idx := range upcoming {
+		quiz.WriteByte(tetriminoNames[idx])
+	}
+	page.comment = quiz.String()
+
+	return encodeFumen(page), nil
+}
+
+// ImportFumen reset the game and load the first page of a v115 fumen.
+// The operation piece (or else the quiz current piece) becomes the
+// current tetrimino, and quiz next pieces are dealt before the bag.
+// Call Continue to play from the loaded position. A fumen that can not
+// be loaded leaves the game as it is.
+func (gm *GameManager) ImportFumen(data string) error {
+	page, err := decodeFumen(data)
+	if err != nil {
+		return err
+	}
+	if gm.width != fumenWidth {
+		return fmt.Errorf("fumen requires width %d, got %d", fumenWidth, gm.width)
+	}
+
+	// the running game is kept until the whole position is checked
+	playfield := make([]int, gm.width*(gm.height+gm.bufferHeight))
+	// skip the garbage row below the field
+	for i, block := range page.field[:fumenFieldBlocks-fumenWidth] {
+		if block == fumenPieceEmpty {
+			continue
+		}
+		x, y := i%fumenWidth, fumenFieldTop-i/fumenWidth-1
+		if !gm.checkBorderY(y) {
+			return fmt.Errorf("fumen row %d is above playfield", y)
+		}
+		playfield[x+y*gm.width] = tileFromFumenPiece(block)
+	}
+
+	hold := 0
+	var queue []int
+	if match := fumenQuizPattern.FindStringSubmatch(page.comment); match != nil {
+		if match[1] != "" {
+			hold = strings.Index(tetriminoNames, match[1]) + 1
+		}
+		for _, name := range match[2] + match[3] {
+			queue = append(queue, strings.IndexRune(tetriminoNames, name))
+		}
+		if match[2] != "" && page.piece != fumenPieceEmpty {
+			queue = queue[1:] // current is the operation piece
+		}
+	}
+
+	active := page.piece != fumenPieceEmpty && page.piece != fumenPieceGray
+	idx, drct, tetriminoX, tetriminoY := 0, 0, 0, 0
+	if active {
+		for i, piece := range fumenPieces {
+			if piece == page.piece {
+				idx = i
+			}
+		}
+		for i, rotation := range fumenRotations {
+			if rotation == page.rotation {
+				drct = i
+			}
+		}
+		boardX, boardY := fumenAnchor(tetriminoMinos(idx, drct))
+		centerX, centerY := fumenAnchor(fumenMinos(page.piece, page.rotation))
+		tetriminoX, tetriminoY = page.x+centerX-boardX, page.y+centerY-boardY
+		for _, mino := range tetriminoMinos(idx, drct) {
+			x, y := tetriminoX+mino[0], tetriminoY+mino[1]
+			if !gm.checkBorderX(x) || !gm.checkBorderY(y) || playfield[x+y*gm.width] != 0 {
+				return errors.New("fumen operation piece collides with playfield")
+			}
+		}
+	}
+
+	gm.reload()
+	copy(gm.playfield, playfield)
+	if hold > 0 {
+		if len(gm.stashQueue) == 0 {
+			gm.stashQueue = make([]int, 1)
+		}
+		gm.stashQueue[0] = hold
+	}
+	if active {
+		gm.tetriminoIdx, gm.tetriminoDrct = idx, drct
+		gm.tetriminoX, gm.tetriminoY = tetriminoX, tetriminoY
+		gm.activeFlag = true
+		gm.finesseSkipFlag = true // not placed from spawn
+		gm.calcGhostPos()
+	}
+	gm.presetQueue = queue
+	gm.updateNextTetrimino()
+	return nil
+}
+
+func tileFromFumenPiece(piece int) int {
+	for idx, p := range fumenPieces {
+		if p == piece {
+			return idx + 1
+		}
+	}
+	return garbageTile
+}
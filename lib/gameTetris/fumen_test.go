@@ -0,0 +1,143 @@
+package gameTetris
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameManager_ImportFumenEmpty(t *testing.T) {
+	g := newTestGameManager()
+	assert.NoError(t, g.ImportFumen("v115@vhAAgH"))
+	for _, tile := range g.playfield {
+		assert.Equal(t, emptyTile, tile)
+	}
+	assert.False(t, g.activeFlag, "empty fumen should have no current tetrimino")
+}
+
+func TestGameManager_ImportFumenInvalid(t *testing.T) {
+	g := newTestGameManager()
+	assert.Error(t, g.ImportFumen("v110@vhAAgH"))
+	assert.Error(t, g.ImportFumen("v115@vh"))
+	assert.Error(t, g.ImportFumen("v115@!!AAgH"))
+}
+
+func TestGameManager_ImportFumenRejected(t *testing.T) {
+	g := newTestGameManager()
+	g.reload()
+	g.playfield[5] = garbageTile
+	g.score = 1234
+	playfield := append([]int(nil), g.playfield...)
+	// T on a gray block at the bottom left
+	assert.Error(t, g.ImportFumen("v115@bhA8Se1OJ"))
+	assert.Equal(t, playfield, g.playfield, "the game is kept")
+	assert.Equal(t, 1234, g.score)
+	assert.False(t, g.activeFlag)
+}
+
+func TestGameManager_ExportFumenRoundTrip(t *testing.T) {
+	g := newTestGameManager()
+	g.reload()
+	// a T-spin double setup with garbage
+	rows := []string{
+		"GGGGGG_GGG",
+		"GGGGG___GG",
+		"SSJJ_____Z",
+	}
+	for i, row := range rows {
+		for x, c := range row {
+			if c == 'G' {
+				g.playfield[x+i*g.width] = garbageTile
+			} else if c != '_' {
+				g.playfield[x+i*g.width] = tileFromFumenPiece(fumenPieces[strings.IndexRune(tetriminoNames, c)])
+			}
+		}
+	}
+	g.tetriminoIdx, g.tetriminoDrct = tetriminoShapeT, 2
+	g.tetriminoX, g.tetriminoY = 5, 3
+	g.activeFlag = true
+	g.stashQueue = []int{tetriminoShapeI + 1}
+	g.presetQueue = []int{tetriminoShapeS, tetriminoShapeZ, tetriminoShapeO}
+
+	data, err := g.ExportFumen()
+	assert.NoError(t, err)
+
+	loaded := newTestGameManager()
+	assert.NoError(t, loaded.ImportFumen(data))
+	assert.Equal(t, g.playfield, loaded.playfield)
+	assert.True(t, loaded.activeFlag)
+	assert.Equal(t, g.tetriminoIdx, loaded.tetriminoIdx)
+	assert.Equal(t, g.tetriminoDrct, loaded.tetriminoDrct)
+	assert.Equal(t, g.tetriminoX, loaded.tetriminoX)
+	assert.Equal(t, g.tetriminoY, loaded.tetriminoY)
+	assert.Equal(t, g.stashQueue, loaded.stashQueue)
+	assert.Equal(t, g.upcomingTetriminos(), loaded.upcomingTetriminos()[:len(g.upcomingTetriminos())])
+}
+
+func TestGameManager_FumenAllPieces(t *testing.T) {
+	for idx := range tetriminoShapes {
+		for drct := range tetriminoShapes[idx] {
+			g := newTestGameManager()
+			g.reload()
+			g.tetriminoIdx, g.tetriminoDrct = idx, drct
+			g.tetriminoX, g.tetriminoY = 3, 5
+			g.activeFlag = true
+			data, err := g.ExportFumen()
+			assert.NoError(t, err)
+
+			loaded := newTestGameManager()
+			assert.NoError(t, loaded.ImportFumen(data))
+			assert.Equal(t, idx, loaded.tetriminoIdx)
+			assert.Equal(t, drct, loaded.tetriminoDrct)
+			assert.Equal(t, 3, loaded.tetriminoX, "shape %d facing %d", idx, drct)
+			assert.Equal(t, 5, loaded.tetriminoY, "shape %d facing %d", idx, drct)
+		}
+	}
+}
+
+// fumen strings by the v115 spec: field blocks and operation pieces in
+// every rotation, on the board they show
+func TestGameManager_ImportFumenFixtures(t *testing.T) {
+	for data, rows := range map[string][]string{
+		"v115@9gF8DeF8DeF8DeF8NeAgH":   {"XXXXXX....", "XXXXXX....", "XXXXXX....", "XXXXXX...."},
+		"v115@bhwhglQpAtwwg0Q4A8LeAgH": {"ILOZTJSX.."},
+	} {
+		g, want := newTestGameManager(), newTestGameManager()
+		assert.NoError(t, g.ImportFumen(data))
+		assert.NoError(t, want.loadPuzzle(&Puzzle{rows: rows}))
+		assert.Equal(t, want.playfield, g.playfield, data)
+		assert.False(t, g.activeFlag, data)
+	}
+
+	for _, c := range []struct {
+		data  string
+		piece byte
+		rows  []string
+	}{
+		{"v115@vhATJJ", 'O', []string{"XX........", "XX........"}},                             // spawn
+		{"v115@vhAbLJ", 'O', []string{"....XX....", "....XX...."}},                             // left
+		{"v115@vhADNJ", 'O', []string{"........XX", "........XX"}},                             // reverse
+		{"v115@vhAhQJ", 'I', []string{"....XXXX.."}},                                           // reverse
+		{"v115@vhAZEJ", 'I', []string{"X.........", "X.........", "X.........", "X........."}}, // left
+		{"v115@vhAXLJ", 'S', []string{"....XX....", "...XX....."}},                             // spawn
+		{"v115@vhAPNJ", 'S', []string{".......X..", ".......XX.", "........X."}},               // right
+		{"v115@vhAULJ", 'Z', []string{"...XX.....", "....XX...."}},                             // spawn
+		{"v115@vhAcJJ", 'Z', []string{".X........", "XX........", "X........."}},               // left
+		{"v115@vhAVQJ", 'T', []string{"....X.....", "...XXX...."}},                             // spawn
+		{"v115@vhA6NJ", 'L', []string{"........XX", ".........X", ".........X"}},               // left
+		{"v115@vhAOJJ", 'J', []string{"XX........", "X.........", "X........."}},               // right
+	} {
+		g, want := newTestGameManager(), newTestGameManager()
+		assert.NoError(t, g.ImportFumen(c.data))
+		assert.NoError(t, want.loadPuzzle(&Puzzle{rows: c.rows}))
+		assert.True(t, g.activeFlag, c.data)
+		assert.Equal(t, string(c.piece), string(tetriminoNames[g.tetriminoIdx]), c.data)
+		minos := make([]int, len(g.playfield))
+		for i := tetriminoShapes[g.tetriminoIdx][g.tetriminoDrct]; i != 0; i >>= tetriNum {
+			x, y := g.calcMinoPosOnBoard(i)
+			minos[x+y*g.width] = garbageTile
+		}
+		assert.Equal(t, want.playfield, minos, c.data)
+	}
+}
@@ -1,7 +1,11 @@
 package gameTetris
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
 
 	"github.com/mattn/go-runewidth"
 	"github.com/nsf/termbox-go"
@@ -16,78 +20,396 @@ var colorMap = []termbox.Attribute{
 	termbox.ColorYellow,
 	termbox.ColorGreen,
 	termbox.ColorRed,
+	termbox.ColorDarkGray,
 }
 
+var pauseMenuNames = [pauseMenuItemNum]string{"Resume", "Restart", "Quit"}
+
+// start menu items, shown when a saved game exists
+const (
+	startMenuContinue = iota
+	startMenuNewGame
+	startMenuItemNum
+)
+
+var startMenuNames = [startMenuItemNum]string{"Continue", "New Game"}
+
 //  =================== Utils ===================
 
 // ListenToInput listen all input event and push into channel
-func ListenToInput(inputCh chan int) {
+// until stopCh is closed (call termbox.Interrupt to wake it up)
+func ListenToInput(inputCh chan int, stopCh <-chan struct{}) {
 	termbox.SetInputMode(termbox.InputEsc)
 	for {
+		input := -1
 		switch ev := termbox.PollEvent(); ev.Type {
 		case termbox.EventKey:
-			switch ev.Key {
-			case termbox.KeyArrowLeft:
-				inputCh <- moveLeft
-			case termbox.KeyArrowRight:
-				inputCh <- moveRight
-			case termbox.KeyArrowUp:
-				inputCh <- rotateClockwise
-			case termbox.KeyArrowDown:
-				inputCh <- softDrop
-			case termbox.KeySpace:
-				inputCh <- hardDrop
-
-			case termbox.KeyEsc:
-				panic("bye")
-			}
-			switch ev.Ch {
-			case 'x':
-				inputCh <- rotateClockwise
-			case 'z':
-				inputCh <- rotateCounterClockwise
+			if action, ok := bindings.Action(ev); ok {
+				input = actionOps[action]
 			}
 
+		case termbox.EventInterrupt:
 		case termbox.EventError:
 			panic(ev.Err)
 		}
+		if input < 0 {
+			select {
+			case <-stopCh:
+				return
+			default:
+				continue
+			}
+		}
+		select {
+		case inputCh <- input:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// start termbox and input listener, the returned function shuts both down
+func startTermbox(inputCh chan int) (func(), error) {
+	if err := termbox.Init(); err != nil {
+		return nil, err
+	}
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		ListenToInput(inputCh, stopCh)
+		close(doneCh)
+	}()
+	return func() {
+		close(stopCh)
+		termbox.Interrupt()
+		<-doneCh
+		termbox.Close()
+	}, nil
+}
+
+// spectators of games started by frontends in this file
+var broadcaster *Broadcaster
+
+// ServeSpectators publish games started by Run, RunMarathon, RunPractice
+// and RunPuzzles to spectators on network ("tcp" or "unix") address
+func ServeSpectators(network, address string) (io.Closer, error) {
+	b, err := Serve(network, address)
+	if err != nil {
+		return nil, err
+	}
+	broadcaster = b
+	return b, nil
+}
+
+func screenRenderer() Renderer {
+	if broadcaster != nil {
+		return MultiRenderer{TermboxRenderer{}, broadcaster}
+	}
+	return TermboxRenderer{}
+}
+
+// playfield of games started by Run, RunMarathon and RunPractice,
+// puzzles bring their own
+var screenBoard = boardPresets["standard"]
+
+// UseBoard play on a board preset (see BoardNames) from now on
+func UseBoard(name string) error {
+	board, err := BoardPreset(name)
+	if err != nil {
+		return err
+	}
+	screenBoard = board
+	return nil
+}
+
+func newScreenGameManager(inputCh chan int) (*GameManager, error) {
+	gm := NewGameManager(inputCh, screenRenderer())
+	if err := gm.SetupBoard(screenBoard); err != nil {
+		return nil, err
+	}
+	return gm, nil
+}
+
+// Spectate watch a game published on network address until it ends or
+// pause key is pressed
+func Spectate(network, address string) error {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := termbox.Init(); err != nil {
+		return err
+	}
+	defer termbox.Close()
+
+	// leaving closes the connection, which wakes the reader up
+	leaveCh, doneCh := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		left := false
+		for {
+			ev := termbox.PollEvent()
+			if ev.Type == termbox.EventInterrupt {
+				return
+			}
+			if action, ok := bindings.Action(ev); ok && actionOps[action] == pause && !left {
+				left = true
+				close(leaveCh)
+				conn.Close()
+			}
+		}
+	}()
+	defer func() {
+		termbox.Interrupt()
+		<-doneCh
+	}()
+
+	fr := NewFrameReader(conn)
+	for {
+		frame, err := fr.Read()
+		if err != nil {
+			select {
+			case <-leaveCh:
+				return nil
+			default:
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		TermboxRenderer{}.Render(frame)
 	}
 }
 
-// RenderToScreen render game infomation to screen
-func RenderToScreen(playfield, next []int, height, width, score, highScore, level,
-	tSpinCount, tetrisCount, comboCount int) {
+// Run is the entrance of tetris in cmd, it returns the final score
+// once the game is over or quit from pause menu. A game quit from pause
+// menu is saved to savePath and offered to continue next time, an empty
+// savePath disables saving.
+func Run(highScore int, savePath string) (int, error) {
+	inputCh := make(chan int, 5)
+	stop, err := startTermbox(inputCh)
+	if err != nil {
+		return 0, err
+	}
+	defer stop()
+
+	gm, err := newScreenGameManager(inputCh)
+	if err != nil {
+		return 0, err
+	}
+	gm.LoadHighScore(highScore)
+	resumeFlag := false
+	if _, err := os.Stat(savePath); savePath != "" && err == nil {
+		switch startMenu(inputCh) {
+		case startMenuContinue:
+			if err := gm.LoadState(savePath); err != nil {
+				return 0, err
+			}
+			resumeFlag = true
+		case -1:
+			return 0, nil
+		}
+	}
+	if resumeFlag {
+		gm.Continue()
+		if gm.retryFlag {
+			gm.NewGame()
+		}
+	} else {
+		gm.NewGame()
+	}
+	if savePath == "" {
+		return gm.Score(), nil
+	}
+	if gm.QuitRequested() {
+		return gm.Score(), gm.SaveState(savePath)
+	}
+	// game over, nothing left to continue
+	if err := os.Remove(savePath); err != nil && !os.IsNotExist(err) {
+		return gm.Score(), err
+	}
+	return gm.Score(), nil
+}
+
+// RunMarathon is the entrance of tetris marathon mode in cmd, results are
+// shown once the game ends
+func RunMarathon(m Marathon, highScore int) (Results, error) {
+	inputCh := make(chan int, 5)
+	stop, err := startTermbox(inputCh)
+	if err != nil {
+		return Results{}, err
+	}
+	defer stop()
+
+	gm, err := newScreenGameManager(inputCh)
+	if err != nil {
+		return Results{}, err
+	}
+	gm.LoadHighScore(highScore)
+	results, err := gm.PlayMarathon(m)
+	if err != nil || gm.QuitRequested() {
+		return results, err
+	}
+	renderResults(results)
+	if err := termbox.Flush(); err != nil {
+		return results, err
+	}
+	for input := range inputCh {
+		if input == hardDrop || input == pause {
+			break
+		}
+	}
+	return results, nil
+}
+
+func renderResults(results Results) {
 	if err := termbox.Clear(termbox.ColorDefault, termbox.ColorDefault); err != nil {
 		panic(err)
 	}
-	for i := 0; i < height; i++ {
-		for j := 0; j < width; j++ {
-			if playfield[i*width+j] > 0 {
-				// tbprint(j*2, height-i, colorMap[playfield[i*width+j]], termbox.ColorDefault, fmt.Sprint(playfield[i*width+j]))
-				tbprint(j*2, height-i, colorMap[playfield[i*width+j]], termbox.ColorBlack, "◼")
-			} else {
-				// tbprint(j*2, height-i, termbox.ColorBlack, colorMap[playfield[i*width+j]*-1], fmt.Sprint(playfield[i*width+j]))
-				tbprint(j*2, height-i, termbox.ColorBlack, colorMap[playfield[i*width+j]*-1], "◼")
-			}
+	title, color := "GAME OVER", termbox.ColorRed
+	if results.Completed {
+		title, color = "MARATHON COMPLETE", termbox.ColorGreen
+	}
+	seconds := int(results.Time.Seconds())
+	x := leftPanelWidth
+	tbprint(x, 2, color, termbox.ColorDefault, title)
+	tbprint(x, 4, termbox.ColorCyan, termbox.ColorDefault, fmt.Sprintf("Score:     %8d", results.Score))
+	tbprint(x, 5, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("Time:         %02d:%02d", seconds/60, seconds%60))
+	tbprint(x, 6, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("Lines:     %8d", results.Lines))
+	tbprint(x, 7, termbox.ColorMagenta, termbox.ColorDefault, fmt.Sprintf("Level:     %8d", results.Level))
+	tbprint(x, 8, termbox.ColorGreen, termbox.ColorDefault, fmt.Sprintf("Max Combo: %8d", results.MaxCombo))
+	y := 10
+	for _, name := range clearTypeNames {
+		tbprint(x, y, termbox.ColorYellow, termbox.ColorDefault, fmt.Sprintf("%-19s%3d", name, results.ClearCounts[name]))
+		y++
+	}
+	tbprint(x, y+1, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("[%s] quit", keyHint("hardDrop")))
+}
+
+// RunPractice is the entrance of tetris practice mode in cmd, up to
+// undoLimit locks can be undone (negative for unlimited)
+func RunPractice(undoLimit int) error {
+	inputCh := make(chan int, 5)
+	stop, err := startTermbox(inputCh)
+	if err != nil {
+		return err
+	}
+	defer stop()
 
+	gm, err := newScreenGameManager(inputCh)
+	if err != nil {
+		return err
+	}
+	gm.Practice(undoLimit)
+	return nil
+}
+
+// block until an item is chosen, -1 if player quits
+func startMenu(inputCh chan int) int {
+	idx := startMenuContinue
+	for {
+		if err := termbox.Clear(termbox.ColorDefault, termbox.ColorDefault); err != nil {
+			panic(err)
 		}
+		tbprint(leftPanelWidth+4, 4, termbox.ColorWhite, termbox.ColorDefault, "TETRIS")
+		for i, name := range startMenuNames {
+			fg := termbox.ColorWhite
+			if i == idx {
+				fg = termbox.ColorYellow
+				name = "> " + name
+			}
+			tbprint(leftPanelWidth+2, 6+i, fg, termbox.ColorDefault, name)
+		}
+		if err := termbox.Flush(); err != nil {
+			panic(err)
+		}
+		switch <-inputCh {
+		case rotateClockwise:
+			idx = (idx + startMenuItemNum - 1) % startMenuItemNum
+		case softDrop:
+			idx = (idx + 1) % startMenuItemNum
+		case hardDrop:
+			return idx
+		case pause:
+			return -1
+		}
+	}
+}
+
+// TermboxRenderer render game infomation to screen
+type TermboxRenderer struct{}
 
+// Render frame to screen
+func (TermboxRenderer) Render(frame Frame) {
+	if err := termbox.Clear(termbox.ColorDefault, termbox.ColorDefault); err != nil {
+		panic(err)
+	}
+	l := screenLayout(frame)
+	width, playfield := frame.Width, frame.Playfield
+	for i := 0; i < frame.Height; i++ {
+		for j := 0; j < width; j++ {
+			x, y, ok := l.cell(i, j)
+			if !ok {
+				continue
+			}
+			// a mino covers scale x scale cells in Big mode
+			for k := 0; k < l.scale*l.scale; k++ {
+				cx, cy := x+k%l.scale*2, y+k/l.scale
+				if playfield[i*width+j] > 0 {
+					tbprint(cx, cy, colorMap[playfield[i*width+j]], termbox.ColorBlack, "◼")
+				} else if i >= frame.Skyline && playfield[i*width+j] == 0 {
+					tbprint(cx, cy, termbox.ColorDefault, termbox.ColorDefault, " ")
+				} else {
+					tbprint(cx, cy, termbox.ColorBlack, colorMap[playfield[i*width+j]*-1], "◼")
+				}
+			}
+		}
 	}
+	x := l.panelX
+	tbprint(x, 1, termbox.ColorWhite, termbox.ColorDefault, "Next")
+	tbprint(x+10, 1, termbox.ColorWhite, termbox.ColorDefault, "Hold")
 	for i := 0; i < tetriNum; i++ {
 		for j := 0; j < tetriNum; j++ {
-			// tbprint(j*2, height-i, colorMap[playfield[i*width+j]], termbox.ColorDefault, fmt.Sprint(playfield[i*width+j]))
-			tbprint(22+j*2, 2+i, colorMap[next[i*tetriNum+j]], termbox.ColorBlack, "◼")
-
+			tbprint(x+j*2, 2+i, colorMap[frame.Next[i*tetriNum+j]], termbox.ColorBlack, "◼")
+			tbprint(x+10+j*2, 2+i, colorMap[frame.Hold[i*tetriNum+j]], termbox.ColorBlack, "◼")
 		}
 
 	}
-	tbprint(22, 6, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("Hight Score: %8d", highScore))
-	tbprint(22, 7, termbox.ColorCyan, termbox.ColorDefault, fmt.Sprintf("Score: %8d", score))
-	tbprint(22, 8, termbox.ColorMagenta, termbox.ColorDefault, fmt.Sprintf("Level: %2d", level))
-	tbprint(22, 9, termbox.ColorBlue, termbox.ColorDefault, fmt.Sprintf("T-Spins: %3d", tSpinCount))
-	tbprint(22, 10, termbox.ColorYellow, termbox.ColorDefault, fmt.Sprintf("Tetrises: %3d", tetrisCount))
-	if comboCount > 0 {
-		tbprint(22, 11, termbox.ColorGreen, termbox.ColorDefault, fmt.Sprintf("Combos: %3d", comboCount))
+	tbprint(x, 6, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("Hight Score: %8d", frame.HighScore))
+	tbprint(x, 7, termbox.ColorCyan, termbox.ColorDefault, fmt.Sprintf("Score: %8d", frame.Score))
+	tbprint(x, 8, termbox.ColorMagenta, termbox.ColorDefault, fmt.Sprintf("Level: %2d", frame.Level))
+	if frame.Goal > 0 {
+		tbprint(x+12, 8, termbox.ColorMagenta, termbox.ColorDefault, fmt.Sprintf("Goal: %3d", frame.Goal))
+	}
+	tbprint(x, 9, termbox.ColorBlue, termbox.ColorDefault, fmt.Sprintf("T-Spins: %3d", frame.TSpinCount))
+	tbprint(x, 10, termbox.ColorYellow, termbox.ColorDefault, fmt.Sprintf("Tetrises: %3d", frame.TetrisCount))
+	if frame.ComboCount > 0 {
+		tbprint(x, 11, termbox.ColorGreen, termbox.ColorDefault, fmt.Sprintf("Combos: %3d", frame.ComboCount))
+	}
+	tbprint(x, 12, termbox.ColorRed, termbox.ColorDefault, fmt.Sprintf("Finesse: %3d", frame.FinesseFaults))
+	if frame.Practice {
+		tbprint(x, 13, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("Practice  Undo: %d", frame.Undos))
+		if frame.GravityOff {
+			tbprint(x, 14, termbox.ColorWhite, termbox.ColorDefault, "Gravity: off")
+		}
+		tbprint(x, 15, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("[%s] undo  [%s] gravity", keyHint("undo"), keyHint("toggleGravity")))
+	}
+	if l.stats {
+		renderStatistics(frame.Stats)
+	}
+	if frame.Paused {
+		middle := 1 + l.rows*l.scale/2
+		tbprint(l.fieldX+2, middle-2, termbox.ColorWhite, termbox.ColorDefault, "PAUSED")
+		for i, name := range pauseMenuNames {
+			fg := termbox.ColorWhite
+			if i == frame.PauseMenuIdx {
+				fg = termbox.ColorYellow
+				name = "> " + name
+			}
+			tbprint(l.fieldX, middle+i, fg, termbox.ColorDefault, name)
+		}
 	}
 
 	if err := termbox.Flush(); err != nil {
@@ -95,6 +417,90 @@ func RenderToScreen(playfield, next []int, height, width, score, highScore, leve
 	}
 }
 
+// RunPuzzles play every puzzle in dir one after another, the bundled
+// puzzles if dir is empty. After each puzzle, space moves on and 'r'
+// retries it.
+func RunPuzzles(dir string) error {
+	puzzles, err := BundledPuzzles()
+	if dir != "" {
+		puzzles, err = LoadPuzzles(dir)
+	}
+	if err != nil {
+		return err
+	}
+	inputCh := make(chan int, 5)
+	stop, err := startTermbox(inputCh)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	gm := NewGameManager(inputCh, screenRenderer())
+	for i := 0; i < len(puzzles); i++ {
+		solved, err := gm.PlayPuzzle(puzzles[i])
+		if err != nil {
+			return err
+		}
+		if gm.QuitRequested() {
+			return nil
+		}
+		result, color := "Failed!", termbox.ColorRed
+		if solved {
+			result, color = "Solved!", termbox.ColorGreen
+		}
+		x := screenLayout(gm.frame()).panelX
+		tbprint(x, 13, termbox.ColorWhite, termbox.ColorDefault, puzzles[i].Name)
+		tbprint(x, 14, termbox.ColorWhite, termbox.ColorDefault, puzzles[i].Goal)
+		tbprint(x, 15, color, termbox.ColorDefault, result)
+		tbprint(x, 16, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("[%s] next  [%s] retry  [%s] quit", keyHint("hardDrop"), keyHint("retry"), keyHint("pause")))
+		if err := termbox.Flush(); err != nil {
+			return err
+		}
+	waitInput:
+		for input := range inputCh {
+			switch input {
+			case retry:
+				i--
+				break waitInput
+			case hardDrop:
+				break waitInput
+			case pause:
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// layout of frame on current terminal
+func screenLayout(frame Frame) layout {
+	w, h := termbox.Size()
+	return newLayout(frame, w, h)
+}
+
+func renderStatistics(stats Statistics) {
+	seconds := int(stats.Time.Seconds())
+	tbprint(0, 1, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("Time:   %02d:%02d", seconds/60, seconds%60))
+	tbprint(0, 2, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("Lines:  %6d", stats.Lines))
+	tbprint(0, 3, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("Pieces: %6d", stats.Pieces))
+	tbprint(0, 4, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("Attack: %6d", stats.Attack))
+	tbprint(0, 5, termbox.ColorCyan, termbox.ColorDefault, fmt.Sprintf("PPS: %9.2f", stats.PPS))
+	tbprint(0, 6, termbox.ColorCyan, termbox.ColorDefault, fmt.Sprintf("TPM: %9.2f", stats.TPM))
+	tbprint(0, 7, termbox.ColorCyan, termbox.ColorDefault, fmt.Sprintf("LPM: %9.2f", stats.LPM))
+	tbprint(0, 8, termbox.ColorCyan, termbox.ColorDefault, fmt.Sprintf("APM: %9.2f", stats.APM))
+	for idx, name := range tetriminoNames {
+		tbprint(idx*3, 10, colorMap[idx+1], termbox.ColorDefault, string(name))
+		tbprint(idx*3, 11, termbox.ColorWhite, termbox.ColorDefault, fmt.Sprintf("%d", stats.PieceCounts[string(name)]))
+	}
+	y := 13
+	for _, name := range clearTypeNames {
+		if count := stats.ClearCounts[name]; count > 0 {
+			tbprint(0, y, termbox.ColorYellow, termbox.ColorDefault, fmt.Sprintf("%-19s%3d", name, count))
+			y++
+		}
+	}
+}
+
 // This function is often useful:
 func tbprint(x, y int, fg, bg termbox.Attribute, msg string) {
 	for _, c := range msg {
@@ -0,0 +1,102 @@
+package gameTetris
+
+import (
+	"strings"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/keymap"
+)
+
+// keymap section of tetris in config file
+const keymapSection = "tetris"
+
+// operation of each keymap action
+var actionOps = map[string]int{
+	"moveLeft":               moveLeft,
+	"moveRight":              moveRight,
+	"softDrop":               softDrop,
+	"hardDrop":               hardDrop,
+	"rotateClockwise":        rotateClockwise,
+	"rotateCounterClockwise": rotateCounterClockwise,
+	"rotate180":              rotate180,
+	"retry":                  retry,
+	"pause":                  pause,
+	"undo":                   undoLock,
+	"toggleGravity":          toggleGravity,
+	"nextO":                  chooseNext + tetriminoShapeO,
+	"nextI":                  chooseNext + tetriminoShapeI,
+	"nextT":                  chooseNext + tetriminoShapeT,
+	"nextL":                  chooseNext + tetriminoShapeL,
+	"nextJ":                  chooseNext + tetriminoShapeJ,
+	"nextS":                  chooseNext + tetriminoShapeS,
+	"nextZ":                  chooseNext + tetriminoShapeZ,
+}
+
+var defaultKeymap = keymap.MustNew(map[string][]string{
+	"moveLeft":               {"Left"},
+	"moveRight":              {"Right"},
+	"softDrop":               {"Down"},
+	"hardDrop":               {"Space", "Enter"},
+	"rotateClockwise":        {"Up", "x"},
+	"rotateCounterClockwise": {"z"},
+	"rotate180":              {"a"},
+	"retry":                  {"r"},
+	"pause":                  {"Esc"},
+	"undo":                   {"u"},
+	"toggleGravity":          {"g"},
+	"nextO":                  {"1"},
+	"nextI":                  {"2"},
+	"nextT":                  {"3"},
+	"nextL":                  {"4"},
+	"nextJ":                  {"5"},
+	"nextS":                  {"6"},
+	"nextZ":                  {"7"},
+})
+
+var keymapPresets = map[string]*keymap.Keymap{
+	keymap.DefaultPreset: defaultKeymap,
+	"guideline": defaultKeymap.With(map[string][]string{
+		"hardDrop": {"Space"},
+		"pause":    {"Esc", "F1"},
+	}),
+	"wasd": defaultKeymap.With(map[string][]string{
+		"moveLeft":               {"a", "Left"},
+		"moveRight":              {"d", "Right"},
+		"softDrop":               {"s", "Down"},
+		"hardDrop":               {"w", "Space"},
+		"rotateClockwise":        {"k", "Up"},
+		"rotateCounterClockwise": {"j"},
+		"rotate180":              {"l"},
+	}),
+	"vim": defaultKeymap.With(map[string][]string{
+		"moveLeft":               {"h"},
+		"moveRight":              {"l"},
+		"softDrop":               {"j"},
+		"hardDrop":               {"k", "Space"},
+		"rotateClockwise":        {"f"},
+		"rotateCounterClockwise": {"d"},
+		"rotate180":              {"s"},
+	}),
+}
+
+// key bindings used by ListenToInput
+var bindings = defaultKeymap
+
+// name of first key bound to action, for hints on screen
+func keyHint(action string) string {
+	keys := bindings.Keys(action)
+	if len(keys) == 0 {
+		return "-"
+	}
+	return strings.ToLower(keys[0].String())
+}
+
+// LoadKeymap load key bindings of tetris from config file, a missing file
+// keeps the default keys
+func LoadKeymap(path string) error {
+	km, err := keymap.LoadFile(path, keymapSection, keymapPresets)
+	if err != nil {
+		return err
+	}
+	bindings = km
+	return nil
+}
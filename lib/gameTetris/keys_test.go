@@ -0,0 +1,35 @@
+package gameTetris
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/keymap"
+	"github.com/nsf/termbox-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeymapPresets(t *testing.T) {
+	for name, preset := range keymapPresets {
+		for action := range actionOps {
+			assert.NotEmpty(t, preset.Keys(action), "%s: %s is not bound", name, action)
+		}
+	}
+	action, ok := keymapPresets["vim"].Action(termbox.Event{Type: termbox.EventKey, Ch: 'h'})
+	assert.True(t, ok)
+	assert.Equal(t, moveLeft, actionOps[action])
+}
+
+func TestLoadKeymap(t *testing.T) {
+	defer func() { bindings = defaultKeymap }()
+	path := filepath.Join(t.TempDir(), "keys.conf")
+	assert.NoError(t, os.WriteFile(path, []byte("[tetris]\npreset = wasd\nhardDrop = Enter\n"), 0o644))
+	assert.NoError(t, LoadKeymap(path))
+	assert.Equal(t, []keymap.Key{{Key: termbox.KeyEnter}}, bindings.Keys("hardDrop"))
+	assert.Equal(t, "a", keyHint("moveLeft"))
+	assert.Equal(t, "r", keyHint("retry"))
+
+	assert.NoError(t, os.WriteFile(path, []byte("[tetris]\nfly = f\n"), 0o644))
+	assert.Error(t, LoadKeymap(path))
+}
@@ -0,0 +1,86 @@
+package gameTetris
+
+import "fmt"
+
+const (
+	marathonMaxLevel    = 15
+	defaultMarathonGoal = 10
+)
+
+// Marathon is the classic mode: clear Goal lines to level up, the game is
+// completed once the goal of level 15 is cleared (150 lines from level 1),
+// or goes on forever if Endless.
+type Marathon struct {
+	StartLevel, Goal int
+	Endless          bool
+}
+
+// DefaultMarathon starts from level 1 with 10 lines per level
+func DefaultMarathon() Marathon {
+	return Marathon{StartLevel: 1, Goal: defaultMarathonGoal}
+}
+
+// Results of a finished game
+type Results struct {
+	Statistics
+	Score, Level int
+	// Completed is true if marathon is cleared rather than topped out
+	Completed bool
+}
+
+func (m Marathon) validate() error {
+	if m.StartLevel < 1 || m.StartLevel > marathonMaxLevel {
+		return fmt.Errorf("start level %d is out of [1, %d]", m.StartLevel, marathonMaxLevel)
+	}
+	if m.Goal < 1 {
+		return fmt.Errorf("goal of %d lines per level", m.Goal)
+	}
+	return nil
+}
+
+// level up once goal of current level is cleared
+func (gm *GameManager) checkLevelUp() {
+	gm.levelLines += gm.lastClearLines
+	for gm.levelLines >= gm.marathon.Goal {
+		gm.levelLines -= gm.marathon.Goal
+		if gm.level == marathonMaxLevel && !gm.marathon.Endless {
+			gm.marathonClearedFlag = true
+			gm.endFlag = true
+			return
+		}
+		gm.level++
+	}
+	if gm.softDropFlag {
+		gm.calcDropSpeed()
+	} else {
+		gm.calcFallSpeed()
+	}
+}
+
+// PlayMarathon play marathon mode, starting over on restart. It returns
+// the results once the marathon is completed, the game is over or quit is
+// chosen in pause menu.
+func (gm *GameManager) PlayMarathon(m Marathon) (Results, error) {
+	if err := m.validate(); err != nil {
+		return Results{}, err
+	}
+	for {
+		gm.reload()
+		gm.marathon, gm.level = &m, m.StartLevel
+		gm.calcFallSpeed()
+		gm.Continue()
+		if !gm.retryFlag {
+			return gm.Results(), nil
+		}
+	}
+}
+
+// Results of current game
+func (gm *GameManager) Results() Results {
+	return Results{
+		Statistics: gm.Statistics(),
+		Score:      gm.score,
+		Level:      gm.level,
+		Completed:  gm.marathonClearedFlag,
+	}
+}
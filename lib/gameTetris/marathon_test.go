@@ -0,0 +1,50 @@
+package gameTetris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameManager_checkLevelUp(t *testing.T) {
+	g := newTestGameManager()
+	g.reload()
+	g.marathon = &Marathon{StartLevel: 1, Goal: 10}
+	for i := 0; i < 3; i++ {
+		g.lastClearLines = 4
+		g.checkLevelUp()
+	}
+	assert.Equal(t, 2, g.level)
+	assert.Equal(t, 2, g.levelLines)
+	assert.Equal(t, 8, g.frame().Goal)
+
+	g.level, g.levelLines, g.lastClearLines = marathonMaxLevel, 8, 2
+	g.checkLevelUp()
+	assert.True(t, g.marathonClearedFlag)
+	assert.True(t, g.endFlag)
+	assert.Equal(t, marathonMaxLevel, g.level)
+	assert.True(t, g.Results().Completed)
+
+	g.reload()
+	g.marathon = &Marathon{StartLevel: 1, Goal: 10, Endless: true}
+	g.level, g.levelLines, g.lastClearLines = marathonMaxLevel, 8, 2
+	g.checkLevelUp()
+	assert.False(t, g.endFlag)
+	assert.Equal(t, marathonMaxLevel+1, g.level)
+}
+
+func TestGameManager_PlayMarathon(t *testing.T) {
+	g := newScriptedGameManager(hardDrop)
+	_, err := g.PlayMarathon(Marathon{StartLevel: 0, Goal: 10})
+	assert.Error(t, err)
+	_, err = g.PlayMarathon(Marathon{StartLevel: 1})
+	assert.Error(t, err)
+
+	m := DefaultMarathon()
+	m.StartLevel = 5
+	results, err := g.PlayMarathon(m)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, results.Level)
+	assert.Equal(t, 1, results.Pieces)
+	assert.False(t, results.Completed)
+}
@@ -0,0 +1,56 @@
+package gameTetris
+
+// Practice is a sandbox without game over pressure: locks can be undone,
+// the next tetrimino can be chosen by hand and gravity can be turned off,
+// so tetriminos only move on input and lock down on hard drop.
+
+// Practice play a sandbox game, up to undoLimit locks can be undone
+// (negative for unlimited). It returns once the game is over or quit is
+// chosen in pause menu.
+func (gm *GameManager) Practice(undoLimit int) {
+	for {
+		gm.reload()
+		gm.practiceFlag, gm.undoLimit = true, undoLimit
+		gm.Continue()
+		if !gm.retryFlag {
+			return
+		}
+	}
+}
+
+// remember the state as a tetrimino is dealt, the top of history is always
+// the state of current tetrimino at spawn
+func (gm *GameManager) pushHistory() {
+	gm.history = append(gm.history, gm.snapshot())
+	if gm.undoLimit >= 0 && len(gm.history) > gm.undoLimit+1 {
+		gm.history = append(gm.history[:0], gm.history[len(gm.history)-gm.undoLimit-1:]...)
+	}
+}
+
+// take back the last lock, previous tetrimino returns to spawn
+func (gm *GameManager) undo() {
+	if len(gm.history) < 2 {
+		return
+	}
+	history := gm.history[:len(gm.history)-1]
+	undoLimit, gravityOffFlag := gm.undoLimit, gm.gravityOffFlag
+	pausedDuration := gm.pausedDuration
+	if err := gm.restore(history[len(history)-1]); err != nil {
+		return
+	}
+	gm.practiceFlag, gm.undoLimit, gm.gravityOffFlag = true, undoLimit, gravityOffFlag
+	gm.pausedDuration = pausedDuration
+	gm.history = history
+	gm.finesseInputs, gm.finesseSkipFlag = 0, false
+	gm.softDropFlag = false
+	gm.calcFallSpeed()
+	gm.moveFlag = true
+	gm.checkLanding()
+}
+
+// deal idx after current tetrimino instead of the bag, the bag position
+// is kept
+func (gm *GameManager) chooseNext(idx int) {
+	gm.presetQueue = []int{idx}
+	gm.updateNextTetrimino()
+}
@@ -0,0 +1,73 @@
+package gameTetris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ops to quit from pause menu
+var quitOps = []int{pause, rotateClockwise, hardDrop}
+
+// game manager fed with ops, then quit from pause menu
+func newScriptedGameManager(ops ...int) *GameManager {
+	inputCh := make(chan int, len(ops)+len(quitOps))
+	for _, op := range append(ops, quitOps...) {
+		inputCh <- op
+	}
+	g := newTestGameManager()
+	g.inputCh = inputCh
+	return g
+}
+
+func countTiles(playfield []int) int {
+	count := 0
+	for _, tile := range playfield {
+		if tile != emptyTile {
+			count++
+		}
+	}
+	return count
+}
+
+func TestGameManager_PracticeUndo(t *testing.T) {
+	g := newScriptedGameManager(hardDrop, hardDrop, undoLock)
+	g.Practice(-1)
+	assert.True(t, g.QuitRequested())
+	assert.Equal(t, 1, g.Statistics().Pieces)
+	assert.Equal(t, tetriNum, countTiles(g.playfield))
+	assert.Len(t, g.history, 2)
+	assert.Equal(t, 1, g.frame().Undos)
+	assert.Equal(t, g.tetriminoSpawnY, g.tetriminoY, "undone tetrimino is back at spawn")
+
+	// only the last lock can be undone
+	g = newScriptedGameManager(hardDrop, hardDrop, undoLock, undoLock)
+	g.Practice(1)
+	assert.Equal(t, 1, g.Statistics().Pieces)
+	assert.Len(t, g.history, 1)
+}
+
+func TestGameManager_PracticeChooseNext(t *testing.T) {
+	g := newScriptedGameManager(chooseNext+tetriminoShapeI, hardDrop)
+	g.Practice(0)
+	assert.Equal(t, tetriminoShapeI, g.tetriminoIdx)
+	assert.Equal(t, 0, g.bagIdx, "bag position is kept")
+	assert.Len(t, g.history, 1, "nothing to undo")
+}
+
+func TestGameManager_PracticeInputs(t *testing.T) {
+	g := newTestGameManager()
+	g.inputCh = make(chan int, 2)
+	g.reload()
+	g.inputCh <- toggleGravity
+	g.processInput()
+	assert.False(t, g.gravityOffFlag, "only in practice")
+
+	g.practiceFlag = true
+	g.inputCh <- toggleGravity
+	g.processInput()
+	assert.True(t, g.gravityOffFlag)
+	g.inputCh <- chooseNext + tetriminoShapeZ
+	g.processInput()
+	assert.Equal(t, tetriminoShapeZ, g.nextTetriminoIdx)
+}
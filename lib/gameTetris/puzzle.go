@@ -0,0 +1,269 @@
+package gameTetris
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// puzzle goal type
+const (
+	puzzleGoalLines = iota
+	puzzleGoalTSpin
+	puzzleGoalPerfectClear
+)
+
+const puzzleFileExt = ".txt"
+
+//go:embed puzzles/*.txt
+var bundledPuzzles embed.FS // puzzles shipped with the game
+
+// Puzzle is a starting playfield with a fixed tetrimino sequence and a goal
+// to reach before the sequence runs out. A puzzle file looks like:
+//
+//	# comment
+//	name: T-spin triple
+//	goal: tspin 3          (or "lines 4", "perfect")
+//	pieces: TLI
+//	fumen: v115@...        (or a "board:" line followed by rows)
+//	board:
+//	XXXXXXXX..
+//	XXXXXXXXX.
+//
+// Board rows are written top to bottom; '.' or '_' is empty, 'X' or 'G' is
+// garbage and IOTLJSZ are coloured minos.
+type Puzzle struct {
+	Name, Goal string
+
+	goalType, goalLines int
+	pieces              []int
+	fumen               string
+	rows                []string
+}
+
+// LoadPuzzle read a puzzle from a text file
+func LoadPuzzle(path string) (*Puzzle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readPuzzle(f, path)
+}
+
+// readPuzzle of file in path, named after the file if it has no name
+func readPuzzle(r io.Reader, path string) (*Puzzle, error) {
+	p, err := parsePuzzle(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), puzzleFileExt)
+	}
+	return p, nil
+}
+
+// LoadPuzzles read all puzzle files in dir, ordered by file name
+func LoadPuzzles(dir string) ([]*Puzzle, error) {
+	return loadPuzzles(os.DirFS(dir), dir)
+}
+
+// BundledPuzzles read the puzzles shipped with the game, ordered by file
+// name
+func BundledPuzzles() ([]*Puzzle, error) {
+	fsys, err := fs.Sub(bundledPuzzles, "puzzles")
+	if err != nil {
+		return nil, err
+	}
+	return loadPuzzles(fsys, "puzzles")
+}
+
+// loadPuzzles read all puzzle files in fsys, dir names them in errors
+func loadPuzzles(fsys fs.FS, dir string) ([]*Puzzle, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if pathErr, ok := err.(*fs.PathError); ok {
+		pathErr.Path = dir
+	}
+	if err != nil {
+		return nil, err
+	}
+	puzzles := make([]*Puzzle, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != puzzleFileExt {
+			continue
+		}
+		f, err := fsys.Open(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		p, err := readPuzzle(f, filepath.Join(dir, entry.Name()))
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		puzzles = append(puzzles, p)
+	}
+	return puzzles, nil
+}
+
+func parsePuzzle(r io.Reader) (*Puzzle, error) {
+	p := &Puzzle{goalType: -1}
+	inBoard := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if inBoard {
+			p.rows = append(p.rows, line)
+			continue
+		}
+		sep := strings.Index(line, ":")
+		if sep < 0 {
+			return nil, fmt.Errorf("invalid puzzle line %q", line)
+		}
+		key, value := strings.TrimSpace(line[:sep]), strings.TrimSpace(line[sep+1:])
+		switch key {
+		case "name":
+			p.Name = value
+		case "goal":
+			if err := p.parseGoal(value); err != nil {
+				return nil, err
+			}
+		case "pieces":
+			for _, name := range strings.ToUpper(value) {
+				idx := strings.IndexRune(tetriminoNames, name)
+				if idx < 0 {
+					return nil, fmt.Errorf("unknown tetrimino %q", name)
+				}
+				p.pieces = append(p.pieces, idx)
+			}
+		case "fumen":
+			p.fumen = value
+		case "board":
+			inBoard = true
+		default:
+			return nil, fmt.Errorf("unknown puzzle key %q", key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if p.goalType < 0 {
+		return nil, fmt.Errorf("puzzle has no goal")
+	}
+	if p.fumen == "" && len(p.pieces) == 0 {
+		return nil, fmt.Errorf("puzzle has no pieces")
+	}
+	return p, nil
+}
+
+func (p *Puzzle) parseGoal(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty puzzle goal")
+	}
+	switch fields[0] {
+	case "lines":
+		p.goalType = puzzleGoalLines
+		p.Goal = fmt.Sprintf("Clear %s lines", strings.Join(fields[1:], " "))
+	case "tspin":
+		p.goalType = puzzleGoalTSpin
+		p.Goal = fmt.Sprintf("T-Spin %s", strings.Join(fields[1:], " "))
+	case "perfect":
+		p.goalType = puzzleGoalPerfectClear
+		p.Goal = "Perfect Clear"
+		return nil
+	default:
+		return fmt.Errorf("unknown puzzle goal %q", fields[0])
+	}
+	if len(fields) != 2 {
+		return fmt.Errorf("puzzle goal %q needs a line count", fields[0])
+	}
+	lines, err := strconv.Atoi(fields[1])
+	if err != nil || lines < 1 || (p.goalType == puzzleGoalTSpin && lines > 3) {
+		return fmt.Errorf("invalid line count %q", fields[1])
+	}
+	p.goalLines = lines
+	return nil
+}
+
+func (p *Puzzle) checkGoal(gm *GameManager) bool {
+	switch p.goalType {
+	case puzzleGoalLines:
+		return gm.clearedLines >= p.goalLines
+	case puzzleGoalTSpin:
+		return gm.tSpinFlag && !gm.miniSpinFlag && gm.lastClearLines == p.goalLines
+	case puzzleGoalPerfectClear:
+		if gm.clearedLines == 0 {
+			return false
+		}
+		for _, tile := range gm.playfield {
+			if tile != emptyTile {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// load puzzle board and sequence into a fresh game
+func (gm *GameManager) loadPuzzle(p *Puzzle) error {
+	if p.fumen != "" {
+		if err := gm.ImportFumen(p.fumen); err != nil {
+			return err
+		}
+	} else {
+		gm.reload()
+	}
+	for i, row := range p.rows {
+		y := len(p.rows) - 1 - i
+		if len(row) != gm.width || !gm.checkBorderY(y) {
+			return fmt.Errorf("puzzle board does not fit %dx%d playfield", gm.width, gm.height)
+		}
+		for x, c := range row {
+			tile := emptyTile
+			switch c {
+			case '.', '_':
+			case 'X', 'G':
+				tile = garbageTile
+			default:
+				idx := strings.IndexRune(tetriminoNames, c)
+				if idx < 0 {
+					return fmt.Errorf("unknown board cell %q", c)
+				}
+				tile = idx + 1
+			}
+			gm.playfield[x+y*gm.width] = tile
+		}
+	}
+	if len(p.pieces) > 0 {
+		gm.presetQueue = append([]int(nil), p.pieces...)
+	}
+	gm.puzzle = p
+	gm.updateNextTetrimino()
+	return nil
+}
+
+// PlayPuzzle play the puzzle until its goal is reached or its sequence runs
+// out, starting over whenever retry is pressed. It reports whether the
+// puzzle was solved.
+func (gm *GameManager) PlayPuzzle(p *Puzzle) (bool, error) {
+	for {
+		if err := gm.loadPuzzle(p); err != nil {
+			return false, err
+		}
+		gm.Continue()
+		if !gm.retryFlag {
+			return gm.puzzleSolvedFlag, nil
+		}
+	}
+}
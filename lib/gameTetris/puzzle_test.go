@@ -0,0 +1,106 @@
+package gameTetris
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePuzzle(t *testing.T) {
+	p, err := parsePuzzle(strings.NewReader(`
+# a simple tetris
+name: Well
+goal: lines 4
+pieces: IO
+board:
+XXXXXXXXX.
+XXXXXXXXX.
+XXXXXXXXX.
+XXXXXXXXX.
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Well", p.Name)
+	assert.Equal(t, puzzleGoalLines, p.goalType)
+	assert.Equal(t, 4, p.goalLines)
+	assert.Equal(t, []int{tetriminoShapeI, tetriminoShapeO}, p.pieces)
+	assert.Len(t, p.rows, 4)
+
+	invalidPuzzles := []string{
+		"pieces: T\nboard:\n..........",
+		"goal: tspin 4\npieces: T",
+		"goal: lines 1\npieces: Q",
+		"goal: perfect",
+		"goal: lines\npieces: T",
+	}
+	for _, text := range invalidPuzzles {
+		_, err := parsePuzzle(strings.NewReader(text))
+		assert.Error(t, err, text)
+	}
+}
+
+func TestGameManager_PlayPuzzle(t *testing.T) {
+	p, err := parsePuzzle(strings.NewReader(
+		"goal: lines 1\npieces: I\nboard:\nXXXXXXXXX."))
+	assert.NoError(t, err)
+
+	// stand the I upright and drop it into the well
+	inputCh := make(chan int, 10)
+	for _, op := range []int{rotateClockwise, moveRight, moveRight, moveRight, moveRight, hardDrop} {
+		inputCh <- op
+	}
+	g := newTestGameManager()
+	g.inputCh = inputCh
+	solved, err := g.PlayPuzzle(p)
+	assert.NoError(t, err)
+	assert.True(t, solved)
+
+	// an O can not fill the well, and the sequence runs out
+	p.pieces = []int{tetriminoShapeO}
+	inputCh <- hardDrop
+	solved, err = g.PlayPuzzle(p)
+	assert.NoError(t, err)
+	assert.False(t, solved)
+}
+
+func TestLoadPuzzles(t *testing.T) {
+	puzzles, err := LoadPuzzles("puzzles")
+	assert.NoError(t, err)
+	assert.Len(t, puzzles, 3)
+	assert.Equal(t, "Tetris", puzzles[0].Name)
+
+	bundled, err := BundledPuzzles()
+	assert.NoError(t, err)
+	assert.Equal(t, puzzles, bundled, "bundled puzzles are the shipped files")
+
+	_, err = LoadPuzzles("no-such-dir")
+	assert.Error(t, err)
+}
+
+// intended solutions of shipped puzzles, soft drop lands a piece at once
+// so the rest of ops are played on the ground
+var puzzleSolutions = map[string][]int{
+	"Tetris": {rotateClockwise, moveRight, moveRight, moveRight, moveRight, hardDrop},
+	// drop T upright into the slot, then spin it under the overhang
+	"T-Spin Double": {rotateClockwise, moveLeft, softDrop, rotateClockwise, hardDrop},
+	"Perfect Clear": {hardDrop, moveRight, moveRight, hardDrop},
+}
+
+func TestShippedPuzzles(t *testing.T) {
+	puzzles, err := BundledPuzzles()
+	assert.NoError(t, err)
+	for _, p := range puzzles {
+		ops, ok := puzzleSolutions[p.Name]
+		if !assert.True(t, ok, "solution of %s", p.Name) {
+			continue
+		}
+		g := newScriptedGameManager(ops...)
+		setups := g.GetSetups()
+		setups.DropSpeedRatio = 1e6
+		assert.NoError(t, g.Setup(setups))
+		solved, err := g.PlayPuzzle(p)
+		assert.NoError(t, err)
+		assert.True(t, solved, p.Name)
+		assert.True(t, p.checkGoal(g), p.Name)
+	}
+}
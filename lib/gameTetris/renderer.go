@@ -0,0 +1,134 @@
+package gameTetris
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Frame is a snapshot of everything to be shown on screen. Each frame owns
+// its slices, renderers may keep it but must not modify it.
+type Frame struct {
+	// Width*Height tiles from the bottom row up, the current tetrimino is
+	// positive and its ghost is negative. Rows from Skyline up are the
+	// visible part of buffer zone.
+	Playfield              []int
+	Width, Height, Skyline int
+	// rows up to the highest locked mino, the current tetrimino and its
+	// ghost are left out
+	StackTop int
+	// rows up to the highest mino of current tetrimino, or of where the
+	// next one spawns when there is none
+	PieceTop int
+	// screen cells per side of a mino, 2 in Big mode
+	Scale int
+	// tetriminos in a tetriNum*tetriNum grid, empty when there is none
+	Next, Hold []int
+
+	Score, HighScore, Level             int
+	Goal                                int // lines left to next level
+	TSpinCount, TetrisCount, ComboCount int
+	FinesseFaults                       int
+	Stats                               Statistics
+	Paused                              bool
+	PauseMenuIdx                        int
+	// practice mode, with locks that can be undone
+	Practice, GravityOff bool
+	Undos                int
+}
+
+// Renderer draws frames of a running game
+type Renderer interface {
+	Render(frame Frame)
+}
+
+// NopRenderer draws nothing, for headless runs
+type NopRenderer struct{}
+
+// Render nothing
+func (NopRenderer) Render(frame Frame) {}
+
+// ANSIRenderer draws frames as plain text with ANSI escape codes
+type ANSIRenderer struct {
+	w io.Writer
+}
+
+// NewANSIRenderer return *ANSIRenderer writing to w (e.g. os.Stdout)
+func NewANSIRenderer(w io.Writer) *ANSIRenderer {
+	return &ANSIRenderer{w: w}
+}
+
+// a zero scale, as in frames from before Big mode, draws minos 1x1
+func frameScale(frame Frame) int {
+	if frame.Scale < 1 {
+		return 1
+	}
+	return frame.Scale
+}
+
+// ANSI background color of each tile
+var ansiColorMap = []int{40, 47, 46, 45, 44, 43, 42, 41, 100}
+
+// Render frame from the top-left corner of terminal
+func (r *ANSIRenderer) Render(frame Frame) {
+	w := bufio.NewWriter(r.w)
+	fmt.Fprint(w, "\x1b[H\x1b[2J")
+	panel := []string{
+		fmt.Sprintf("High Score: %8d", frame.HighScore),
+		fmt.Sprintf("Score: %8d", frame.Score),
+		fmt.Sprintf("Level: %2d", frame.Level),
+		fmt.Sprintf("Lines: %4d", frame.Stats.Lines),
+		fmt.Sprintf("T-Spins: %3d", frame.TSpinCount),
+		fmt.Sprintf("Tetrises: %3d", frame.TetrisCount),
+		fmt.Sprintf("Finesse: %3d", frame.FinesseFaults),
+	}
+	if frame.Goal > 0 {
+		panel = append(panel, fmt.Sprintf("Goal: %3d", frame.Goal))
+	}
+	if frame.Practice {
+		panel = append(panel, "", fmt.Sprintf("Practice  Undo: %d", frame.Undos))
+		if frame.GravityOff {
+			panel = append(panel, "Gravity: off")
+		}
+	}
+	if frame.Paused {
+		panel = append(panel, "", "PAUSED")
+		for i, name := range pauseMenuNames {
+			if i == frame.PauseMenuIdx {
+				name = "> " + name
+			}
+			panel = append(panel, name)
+		}
+	}
+	scale := frameScale(frame)
+	row := 0
+	for i := frame.Height - 1; i >= 0; i-- {
+		border := "|"
+		if i >= frame.Skyline {
+			border = ":"
+		}
+		for k := 0; k < scale; k++ {
+			fmt.Fprint(w, border)
+			for j := 0; j < frame.Width; j++ {
+				tile := frame.Playfield[i*frame.Width+j]
+				switch {
+				case tile > 0:
+					fmt.Fprintf(w, "\x1b[%dm%s\x1b[0m", ansiColorMap[tile], strings.Repeat("  ", scale))
+				case tile < 0:
+					fmt.Fprint(w, strings.Repeat("[]", scale))
+				default:
+					fmt.Fprint(w, strings.Repeat("  ", scale))
+				}
+			}
+			fmt.Fprint(w, border)
+			if row < len(panel) {
+				fmt.Fprint(w, "  "+panel[row])
+			}
+			row++
+			fmt.Fprint(w, "\r\n")
+		}
+	}
+	fmt.Fprint(w, "+"+strings.Repeat("--", frame.Width*scale)+"+\r\n")
+	w.Flush()
+}
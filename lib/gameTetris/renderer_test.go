@@ -0,0 +1,76 @@
+package gameTetris
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameManager_frame(t *testing.T) {
+	g := newTestGameManager()
+	g.reload()
+	g.playfield[0] = tetriminoShapeI + 1
+	g.useBagSystem()
+	g.tetriminoIdx, g.tetriminoDrct = tetriminoShapeT, 0
+	g.tetriminoX, g.tetriminoY = 3, 5
+	g.activeFlag = true
+	g.calcGhostPos()
+
+	frame := g.frame()
+	assert.Equal(t, g.width*(g.height+g.visibleBufferHeight), len(frame.Playfield))
+	assert.Equal(t, g.height, frame.Skyline)
+	assert.Equal(t, tetriminoShapeI+1, frame.Playfield[0])
+	assert.Equal(t, tetriminoShapeT+1, frame.Playfield[4+5*g.width], "T nub")
+	assert.Equal(t, -(tetriminoShapeT + 1), frame.Playfield[4+1*g.width], "ghost nub")
+
+	// frame owns its slices
+	frame.Playfield[1] = garbageTile
+	assert.Equal(t, emptyTile, g.playfield[1])
+
+	g.pausedFlag = true
+	frame = g.frame()
+	assert.True(t, frame.Paused)
+	assert.Equal(t, make([]int, len(frame.Playfield)), frame.Playfield, "playfield is hidden while paused")
+}
+
+func TestGameManager_frameGhostAndBuffer(t *testing.T) {
+	g := newTestGameManager()
+	setups := g.GetSetups()
+	setups.AllowGhost = false
+	setups.VisibleBufferHeight = 3
+	assert.Nil(t, g.Setup(setups))
+	g.reload()
+	g.tetriminoIdx, g.tetriminoDrct = tetriminoShapeT, 0
+	g.tetriminoX, g.tetriminoY = 3, g.height+1
+	g.activeFlag = true
+	g.calcGhostPos()
+
+	frame := g.frame()
+	assert.Equal(t, g.height+3, frame.Height)
+	assert.Equal(t, tetriminoShapeT+1, frame.Playfield[4+(g.height+1)*g.width], "spawned piece is visible above skyline")
+	for _, tile := range frame.Playfield {
+		assert.GreaterOrEqual(t, tile, 0, "ghost is not drawn")
+	}
+}
+
+func TestGameManager_Setup(t *testing.T) {
+	g := newTestGameManager()
+	setups := g.GetSetups()
+	setups.VisibleBufferHeight = setups.BufferHeight + 1
+	assert.NotNil(t, g.Setup(setups))
+	setups = g.GetSetups()
+	setups.Width = 3
+	assert.NotNil(t, g.Setup(setups))
+	assert.Equal(t, defaultWidth, g.width)
+}
+
+func TestANSIRenderer_Render(t *testing.T) {
+	g := newTestGameManager()
+	g.reload()
+	g.score = 1234
+	buf := &bytes.Buffer{}
+	NewANSIRenderer(buf).Render(g.frame())
+	assert.Contains(t, buf.String(), "Score:     1234")
+	assert.Equal(t, g.height+g.visibleBufferHeight+1, bytes.Count(buf.Bytes(), []byte("\r\n")))
+}
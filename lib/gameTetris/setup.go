@@ -0,0 +1,124 @@
+package gameTetris
+
+import "fmt"
+
+// Setups is the optional settings of game manager
+type Setups struct {
+	Difficulty, LockDownDelay                  int
+	Height, BufferHeight, Width, StashQueueCap int
+	// buffer zone rows shown above the skyline
+	VisibleBufferHeight int
+	// minos are drawn Scale x Scale, see Board
+	Scale          int
+	DropSpeedRatio float64
+	// Rotate180SRSPlus or Rotate180TetrisOnline
+	Rotate180Kicks                           int
+	AllowRotate180                           bool
+	AllowSRS, AllowGhost, AllowHardDropOp    bool
+	AllowLockDownPeek, AllowPlayAboveSkyline bool
+	AllowForcedAboveSkyline                  bool
+	AllowTopOut, AllowLockOut, AllowBlockOut bool
+}
+
+// GetSetups of game manager
+func (gm *GameManager) GetSetups() Setups {
+	return Setups{
+		Difficulty:              gm.difficulty,
+		LockDownDelay:           gm.lockDownDelay,
+		Height:                  gm.height,
+		BufferHeight:            gm.bufferHeight,
+		Width:                   gm.width,
+		StashQueueCap:           gm.stashQueueCap,
+		VisibleBufferHeight:     gm.visibleBufferHeight,
+		Scale:                   gm.scale,
+		DropSpeedRatio:          gm.dropSpeedRatio,
+		Rotate180Kicks:          gm.rotate180Kicks,
+		AllowRotate180:          gm.allowRotate180,
+		AllowSRS:                gm.allowSRS,
+		AllowGhost:              gm.allowGhost,
+		AllowHardDropOp:         gm.allowHardDropOp,
+		AllowLockDownPeek:       gm.allowLockDownPeek,
+		AllowPlayAboveSkyline:   gm.allowPlayAboveSkyline,
+		AllowForcedAboveSkyline: gm.allowForcedAboveSkyline,
+		AllowTopOut:             gm.allowTopOut,
+		AllowLockOut:            gm.allowLockOut,
+		AllowBlockOut:           gm.allowBlockOut,
+	}
+}
+
+// Setup game optional settings, takes effect from next game
+func (gm *GameManager) Setup(setups Setups) error {
+	if err := setups.validate(); err != nil {
+		return err
+	}
+	gm.difficulty = setups.Difficulty
+	gm.lockDownDelay = setups.LockDownDelay
+	gm.height = setups.Height
+	gm.bufferHeight = setups.BufferHeight
+	gm.width = setups.Width
+	gm.stashQueueCap = setups.StashQueueCap
+	gm.visibleBufferHeight = setups.VisibleBufferHeight
+	gm.scale = setups.Scale
+	gm.dropSpeedRatio = setups.DropSpeedRatio
+	gm.rotate180Kicks = setups.Rotate180Kicks
+	gm.allowRotate180 = setups.AllowRotate180
+	gm.allowSRS = setups.AllowSRS
+	gm.allowGhost = setups.AllowGhost
+	gm.allowHardDropOp = setups.AllowHardDropOp
+	gm.allowLockDownPeek = setups.AllowLockDownPeek
+	gm.allowPlayAboveSkyline = setups.AllowPlayAboveSkyline
+	gm.allowForcedAboveSkyline = setups.AllowForcedAboveSkyline
+	gm.allowTopOut = setups.AllowTopOut
+	gm.allowLockOut = setups.AllowLockOut
+	gm.allowBlockOut = setups.AllowBlockOut
+	return nil
+}
+
+// RestoreDefaultSetup for game manager
+func (gm *GameManager) RestoreDefaultSetup() {
+	gm.difficulty = defaultDifficulty
+	gm.lockDownDelay = defaultLockDownDelay
+	gm.height = defaultHeight
+	gm.bufferHeight = defaultBufferHeight
+	gm.width = defaultWidth
+	gm.stashQueueCap = 0
+	gm.visibleBufferHeight = defaultVisibleBufferHeight
+	gm.scale = defaultScale
+	gm.dropSpeedRatio = defaultDropSpeedRatio
+	gm.rotate180Kicks = defaultRotate180Kicks
+	gm.allowRotate180 = defaultAllowRotate180
+	gm.allowSRS = defaultAllowSRS
+	gm.allowGhost = defaultAllowGhost
+	gm.allowHardDropOp = defaultAllowHardDropOp
+	gm.allowLockDownPeek = defaultAllowLockDownPeek
+	gm.allowPlayAboveSkyline = defaultAllowPlayAboveSkyline
+	gm.allowForcedAboveSkyline = defaultAllowForcedAboveSkyline
+	gm.allowTopOut = defaultAllowTopOut
+	gm.allowLockOut = defaultAllowLockOut
+	gm.allowBlockOut = defaultAllowBlockOut
+}
+
+func (s Setups) validate() error {
+	if s.Width < tetriNum || s.Height < tetriNum {
+		return fmt.Errorf("playfield %dx%d is smaller than a tetrimino", s.Width, s.Height)
+	}
+	if s.Width > maxWidth || s.Height > maxHeight {
+		return fmt.Errorf("playfield %dx%d is larger than %dx%d", s.Width, s.Height, maxWidth, maxHeight)
+	}
+	if s.BufferHeight < tetriNum || s.BufferHeight > maxHeight {
+		return fmt.Errorf("buffer height %d is out of [%d, %d]", s.BufferHeight, tetriNum, maxHeight)
+	}
+	if s.VisibleBufferHeight < 0 || s.VisibleBufferHeight > s.BufferHeight {
+		return fmt.Errorf("visible buffer height %d is out of [0, %d]", s.VisibleBufferHeight, s.BufferHeight)
+	}
+	if s.Scale < 1 || s.Scale > maxScale {
+		return fmt.Errorf("scale %d is out of [1, %d]", s.Scale, maxScale)
+	}
+	if s.Rotate180Kicks < 0 || s.Rotate180Kicks >= rotate180KicksNum {
+		return fmt.Errorf("unknown 180 kick table %d", s.Rotate180Kicks)
+	}
+	if s.LockDownDelay < 0 || s.DropSpeedRatio <= 0 || s.StashQueueCap < 0 {
+		return fmt.Errorf("invalid timing or hold setups")
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package gameTetris
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const snapshotVersion = 1
+
+// snapshot is the complete state of a game in progress, saved as JSON
+type snapshot struct {
+	Version     int           `json:"version"`
+	Setups      Setups        `json:"setups"`
+	Playfield   []int         `json:"playfield"`
+	Bag         []int         `json:"bag"`
+	NextBag     []int         `json:"nextBag"`
+	BagIdx      int           `json:"bagIdx"`
+	PresetQueue []int         `json:"presetQueue,omitempty"`
+	StashQueue  []int         `json:"stashQueue"`
+	Active      bool          `json:"active"`
+	Tetrimino   int           `json:"tetrimino"`
+	X           int           `json:"x"`
+	Y           int           `json:"y"`
+	Facing      int           `json:"facing"`
+	Score       int           `json:"score"`
+	Level       int           `json:"level"`
+	TSpins      int           `json:"tSpins"`
+	Tetrises    int           `json:"tetrises"`
+	Combo       int           `json:"combo"`
+	MaxCombo    int           `json:"maxCombo"`
+	BackToBack  bool          `json:"backToBack"`
+	Lines       int           `json:"lines"`
+	Attack      int           `json:"attack"`
+	Finesse     int           `json:"finesseFaults"`
+	PieceCounts []int         `json:"pieceCounts"`
+	ClearCounts []int         `json:"clearCounts"`
+	Elapsed     time.Duration `json:"elapsed"`
+	RNGState    uint64        `json:"rngState"`
+}
+
+func (gm *GameManager) snapshot() snapshot {
+	return snapshot{
+		Version:     snapshotVersion,
+		Setups:      gm.GetSetups(),
+		Playfield:   append([]int(nil), gm.playfield...),
+		Bag:         append([]int(nil), gm.bag...),
+		NextBag:     append([]int(nil), gm.nextBag...),
+		BagIdx:      gm.bagIdx,
+		PresetQueue: append([]int(nil), gm.presetQueue...),
+		StashQueue:  append([]int(nil), gm.stashQueue...),
+		Active:      gm.activeFlag,
+		Tetrimino:   gm.tetriminoIdx,
+		X:           gm.tetriminoX,
+		Y:           gm.tetriminoY,
+		Facing:      gm.tetriminoDrct,
+		Score:       gm.score,
+		Level:       gm.level,
+		TSpins:      gm.tSpinCount,
+		Tetrises:    gm.tetrisCount,
+		Combo:       gm.comboCount,
+		MaxCombo:    gm.maxComboCount,
+		BackToBack:  gm.backToBackFlag,
+		Lines:       gm.clearedLines,
+		Attack:      gm.attack,
+		Finesse:     gm.finesseFaults,
+		PieceCounts: append([]int(nil), gm.pieceCounts[:]...),
+		ClearCounts: append([]int(nil), gm.clearCounts[:]...),
+		Elapsed:     gm.elapsedTime(),
+		RNGState:    gm.rngSource.State(),
+	}
+}
+
+func (gm *GameManager) restore(s snapshot) error {
+	if s.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+	if s.Setups.Scale == 0 {
+		s.Setups.Scale = defaultScale // saved before Big mode
+	}
+	if err := s.Setups.validate(); err != nil {
+		return err
+	}
+	w, h := s.Setups.Width, s.Setups.Height+s.Setups.BufferHeight
+	if len(s.Playfield) != w*h {
+		return fmt.Errorf("playfield has %d tiles, want %d", len(s.Playfield), w*h)
+	}
+	if len(s.Bag) != len(tetriminoShapes) || len(s.NextBag) != len(tetriminoShapes) ||
+		s.BagIdx < -1 || s.BagIdx >= len(s.Bag) {
+		return fmt.Errorf("invalid bag")
+	}
+	if s.Tetrimino < 0 || s.Tetrimino >= len(tetriminoShapes) ||
+		s.Facing < 0 || s.Facing >= len(tetriminoShapes[s.Tetrimino]) {
+		return fmt.Errorf("invalid tetrimino %d facing %d", s.Tetrimino, s.Facing)
+	}
+	if len(s.PieceCounts) != len(gm.pieceCounts) || len(s.ClearCounts) != len(gm.clearCounts) {
+		return fmt.Errorf("invalid statistics")
+	}
+	if err := gm.Setup(s.Setups); err != nil {
+		return err
+	}
+	gm.reload()
+	copy(gm.playfield, s.Playfield)
+	copy(gm.bag, s.Bag)
+	copy(gm.nextBag, s.NextBag)
+	gm.bagIdx = s.BagIdx
+	gm.presetQueue = append([]int(nil), s.PresetQueue...)
+	gm.stashQueue = append([]int(nil), s.StashQueue...)
+	gm.activeFlag = s.Active
+	gm.tetriminoIdx, gm.tetriminoDrct = s.Tetrimino, s.Facing
+	gm.tetriminoX, gm.tetriminoY = s.X, s.Y
+	gm.score, gm.level = s.Score, s.Level
+	gm.tSpinCount, gm.tetrisCount = s.TSpins, s.Tetrises
+	gm.comboCount, gm.maxComboCount = s.Combo, s.MaxCombo
+	gm.backToBackFlag = s.BackToBack
+	gm.clearedLines, gm.attack = s.Lines, s.Attack
+	gm.finesseFaults = s.Finesse
+	copy(gm.pieceCounts[:], s.PieceCounts)
+	copy(gm.clearCounts[:], s.ClearCounts)
+	gm.resumedDuration = s.Elapsed
+	gm.rngSource.SetState(s.RNGState)
+	gm.updateNextTetrimino()
+	gm.calcFallSpeed()
+	if gm.activeFlag {
+		// moves made after the snapshot are not judged
+		gm.finesseSkipFlag = true
+		gm.calcGhostPos()
+	}
+	return nil
+}
+
+// SaveState write complete state of current game to a JSON file,
+// puzzles can not be saved
+func (gm *GameManager) SaveState(path string) error {
+	if gm.puzzle != nil {
+		return fmt.Errorf("puzzle %q can not be saved", gm.puzzle.Name)
+	}
+	if gm.playfield == nil {
+		return fmt.Errorf("no game to save")
+	}
+	data, err := json.MarshalIndent(gm.snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadState restore a game written by SaveState, call Continue to resume it
+func (gm *GameManager) LoadState(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := gm.restore(s); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
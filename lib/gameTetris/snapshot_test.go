@@ -0,0 +1,64 @@
+package gameTetris
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameManager_SaveState(t *testing.T) {
+	g := newTestGameManager()
+	g.Seed(1)
+	g.reload()
+	for i := 0; i < 10; i++ {
+		g.useBagSystem()
+	}
+	g.playfield[3] = garbageTile
+	g.tetriminoX, g.tetriminoY, g.tetriminoDrct = 2, 10, 3
+	g.activeFlag = true
+	g.stashQueue = []int{tetriminoShapeS + 1}
+	g.score, g.level, g.comboCount, g.backToBackFlag = 4200, 3, 2, true
+	g.clearCounts[clearTSpinDouble] = 1
+	g.resumedDuration = time.Minute
+
+	path := filepath.Join(t.TempDir(), "save", "tetris.json")
+	assert.NoError(t, g.SaveState(path))
+
+	loaded := newTestGameManager()
+	loaded.Seed(2)
+	assert.NoError(t, loaded.LoadState(path))
+	assert.Equal(t, g.snapshot(), loaded.snapshot())
+	assert.True(t, loaded.finesseSkipFlag)
+
+	// the bag system carries on with the same random sequence
+	for i := 0; i < 20; i++ {
+		g.useBagSystem()
+		loaded.useBagSystem()
+		assert.Equal(t, g.tetriminoIdx, loaded.tetriminoIdx)
+	}
+}
+
+func TestGameManager_SaveStatePuzzle(t *testing.T) {
+	p, err := parsePuzzle(strings.NewReader("goal: lines 1\npieces: I"))
+	assert.NoError(t, err)
+	g := newTestGameManager()
+	assert.NoError(t, g.loadPuzzle(p))
+	assert.Error(t, g.SaveState(filepath.Join(t.TempDir(), "puzzle.json")))
+}
+
+func TestGameManager_restoreInvalid(t *testing.T) {
+	g := newTestGameManager()
+	g.reload()
+	s := g.snapshot()
+	s.Playfield = s.Playfield[1:]
+	assert.Error(t, g.restore(s))
+	s = g.snapshot()
+	s.Version = 0
+	assert.Error(t, g.restore(s))
+	s = g.snapshot()
+	s.Facing = 4
+	assert.Error(t, g.restore(s))
+}
@@ -0,0 +1,154 @@
+package gameTetris
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// spectators receive at most this many frames per second
+const spectateFPS = 60
+
+// spectators not taking a frame in this time are disconnected
+const spectateWriteTimeout = 5 * time.Second
+
+// MultiRenderer draws each frame with all of its renderers
+type MultiRenderer []Renderer
+
+// Render frame with every renderer
+func (renderers MultiRenderer) Render(frame Frame) {
+	for _, r := range renderers {
+		r.Render(frame)
+	}
+}
+
+// Broadcaster is a Renderer publishing frames to spectators connected over
+// a TCP or Unix socket, one JSON frame per line. Render never blocks: each
+// spectator is sent the latest frame, frames it is too slow for are dropped.
+type Broadcaster struct {
+	listener net.Listener
+
+	mu         sync.Mutex
+	spectators map[*spectator]struct{}
+	closed     bool
+}
+
+type spectator struct {
+	conn    net.Conn
+	mu      sync.Mutex
+	frame   *Frame
+	frameCh chan struct{} // signaled when frame is replaced
+}
+
+// Serve listen on network ("tcp" or "unix") address for spectators
+func Serve(network, address string) (*Broadcaster, error) {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	b := &Broadcaster{listener: listener, spectators: map[*spectator]struct{}{}}
+	go b.accept()
+	return b, nil
+}
+
+// Addr spectators connect to
+func (b *Broadcaster) Addr() net.Addr {
+	return b.listener.Addr()
+}
+
+func (b *Broadcaster) accept() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return // closed
+		}
+		s := &spectator{conn: conn, frameCh: make(chan struct{}, 1)}
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			conn.Close()
+			return
+		}
+		b.spectators[s] = struct{}{}
+		b.mu.Unlock()
+		go b.serve(s)
+	}
+}
+
+// write frames to spectator until it leaves
+func (b *Broadcaster) serve(s *spectator) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.spectators, s)
+		b.mu.Unlock()
+		s.conn.Close()
+	}()
+	w := bufio.NewWriter(s.conn)
+	encoder := json.NewEncoder(w)
+	for range s.frameCh {
+		s.mu.Lock()
+		frame := s.frame
+		s.mu.Unlock()
+		if err := s.conn.SetWriteDeadline(time.Now().Add(spectateWriteTimeout)); err != nil {
+			return
+		}
+		if err := encoder.Encode(frame); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+		time.Sleep(time.Second / spectateFPS)
+	}
+}
+
+// Render publish frame to all spectators
+func (b *Broadcaster) Render(frame Frame) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.spectators {
+		s.mu.Lock()
+		s.frame = &frame
+		s.mu.Unlock()
+		select {
+		case s.frameCh <- struct{}{}:
+		default: // spectator is busy, it picks the latest frame up later
+		}
+	}
+}
+
+// Close stop listening and disconnect all spectators
+func (b *Broadcaster) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for s := range b.spectators {
+		close(s.frameCh)
+		s.conn.Close() // a spectator may be stuck in a write
+		delete(b.spectators, s)
+	}
+	return b.listener.Close()
+}
+
+// FrameReader reads frames published by a Broadcaster
+type FrameReader struct {
+	decoder *json.Decoder
+}
+
+// NewFrameReader return *FrameReader reading from r (e.g. a net.Conn)
+func NewFrameReader(r io.Reader) *FrameReader {
+	return &FrameReader{decoder: json.NewDecoder(r)}
+}
+
+// Read next frame, io.EOF once the game is gone
+func (fr *FrameReader) Read() (Frame, error) {
+	var frame Frame
+	err := fr.decoder.Decode(&frame)
+	return frame, err
+}
@@ -0,0 +1,142 @@
+package gameTetris
+
+import (
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordRenderer struct {
+	frames []Frame
+}
+
+func (r *recordRenderer) Render(frame Frame) {
+	r.frames = append(r.frames, frame)
+}
+
+func TestMultiRenderer(t *testing.T) {
+	a, b := &recordRenderer{}, &recordRenderer{}
+	MultiRenderer{a, b}.Render(Frame{Score: 7})
+	assert.Equal(t, []Frame{{Score: 7}}, a.frames)
+	assert.Equal(t, []Frame{{Score: 7}}, b.frames)
+}
+
+func (b *Broadcaster) spectatorNum() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.spectators)
+}
+
+func testBroadcaster(t *testing.T, network, address string) {
+	b, err := Serve(network, address)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer b.Close()
+	conns := make([]net.Conn, 2)
+	for i := range conns {
+		conns[i], err = net.Dial(network, b.Addr().String())
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conns[i].Close()
+	}
+	assert.Eventually(t, func() bool { return b.spectatorNum() == len(conns) },
+		time.Second, time.Millisecond)
+
+	g := newTestGameManager()
+	g.reload()
+	g.score = 1234
+	frame := g.frame()
+	b.Render(frame)
+	for _, conn := range conns {
+		got, err := NewFrameReader(conn).Read()
+		assert.NoError(t, err)
+		assert.Equal(t, frame.Playfield, got.Playfield)
+		assert.Equal(t, 1234, got.Score)
+	}
+
+	// a spectator leaving does not bother the others
+	conns[0].Close()
+	assert.Eventually(t, func() bool {
+		b.Render(frame)
+		return b.spectatorNum() == 1
+	}, time.Second, time.Millisecond)
+
+	fr := NewFrameReader(conns[1])
+	b.Close()
+	for {
+		if _, err = fr.Read(); err != nil {
+			break
+		}
+	}
+	assert.Equal(t, io.EOF, err, "closing ends the stream")
+	assert.NoError(t, b.Close())
+}
+
+func TestBroadcaster(t *testing.T) {
+	t.Run("tcp", func(t *testing.T) {
+		testBroadcaster(t, "tcp", "127.0.0.1:0")
+	})
+	t.Run("unix", func(t *testing.T) {
+		testBroadcaster(t, "unix", filepath.Join(t.TempDir(), "tetris.sock"))
+	})
+}
+
+func TestBroadcaster_renderNeverBlocks(t *testing.T) {
+	b, err := Serve("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer b.Close()
+	conn, err := net.Dial("tcp", b.Addr().String())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	assert.Eventually(t, func() bool { return b.spectatorNum() == 1 },
+		time.Second, time.Millisecond)
+
+	// spectator reads nothing, frames it misses are dropped
+	frame := newTestGameManager().frame()
+	start := time.Now()
+	for i := 0; i < 10000; i++ {
+		frame.Score = i
+		b.Render(frame)
+	}
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestBroadcaster_closeSpectatorNotReading(t *testing.T) {
+	b, err := Serve("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer b.Close()
+	// writes to a pipe block until the other end reads
+	conn, peer := net.Pipe()
+	defer peer.Close()
+	s := &spectator{conn: conn, frameCh: make(chan struct{}, 1)}
+	b.mu.Lock()
+	b.spectators[s] = struct{}{}
+	b.mu.Unlock()
+	doneCh := make(chan struct{})
+	go func() {
+		b.serve(s)
+		close(doneCh)
+	}()
+
+	b.Render(newTestGameManager().frame())
+	assert.Eventually(t, func() bool { return len(s.frameCh) == 0 },
+		time.Second, time.Millisecond, "spectator is writing the frame")
+	assert.NoError(t, b.Close())
+	select {
+	case <-doneCh:
+	case <-time.After(time.Second):
+		t.Error("spectator is not disconnected by Close")
+	}
+}
@@ -0,0 +1,129 @@
+package gameTetris
+
+import "time"
+
+// clear type of a lock down
+const (
+	clearSingle = iota
+	clearDouble
+	clearTriple
+	clearTetris
+	clearMiniTSpin
+	clearMiniTSpinSingle
+	clearMiniTSpinDouble
+	clearTSpin
+	clearTSpinSingle
+	clearTSpinDouble
+	clearTSpinTriple
+	clearPerfect
+	clearTypeNum
+)
+
+var clearTypeNames = [clearTypeNum]string{
+	"Single", "Double", "Triple", "Tetris",
+	"Mini T-Spin", "Mini T-Spin Single", "Mini T-Spin Double",
+	"T-Spin", "T-Spin Single", "T-Spin Double", "T-Spin Triple",
+	"Perfect Clear",
+}
+
+// score awarded per level, indexed by clear type
+var clearScores = [clearTypeNum]int{100, 300, 500, 800, 100, 200, 400, 400, 800, 1200, 1600, 0}
+
+// garbage lines sent, indexed by clear type
+var clearAttacks = [clearTypeNum]int{0, 1, 2, 4, 0, 0, 1, 0, 2, 4, 6, 10}
+
+// extra garbage lines sent, indexed by combo count
+var comboAttacks = []int{0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 4, 5}
+
+// Statistics of current game
+type Statistics struct {
+	Lines, Pieces, Attack, MaxCombo int
+	Time                            time.Duration
+	// pieces per second, tetriminos/lines/attack per minute
+	PPS, TPM, LPM, APM float64
+	// placed tetriminos by name ("OITLJSZ") and lock downs by clear type
+	PieceCounts, ClearCounts map[string]int
+}
+
+// Statistics of current game
+func (gm *GameManager) Statistics() Statistics {
+	stats := Statistics{
+		Lines:       gm.clearedLines,
+		Attack:      gm.attack,
+		MaxCombo:    gm.maxComboCount,
+		Time:        gm.elapsedTime(),
+		PieceCounts: make(map[string]int, len(gm.pieceCounts)),
+		ClearCounts: make(map[string]int, len(gm.clearCounts)),
+	}
+	for idx, count := range gm.pieceCounts {
+		stats.Pieces += count
+		stats.PieceCounts[string(tetriminoNames[idx])] = count
+	}
+	for clearType, count := range gm.clearCounts {
+		stats.ClearCounts[clearTypeNames[clearType]] = count
+	}
+	if minutes := stats.Time.Minutes(); minutes > 0 {
+		stats.PPS = float64(stats.Pieces) / stats.Time.Seconds()
+		stats.TPM = float64(stats.Pieces) / minutes
+		stats.LPM = float64(stats.Lines) / minutes
+		stats.APM = float64(stats.Attack) / minutes
+	}
+	return stats
+}
+
+func (gm *GameManager) elapsedTime() time.Duration {
+	if gm.startTime.IsZero() {
+		return gm.resumedDuration
+	}
+	return gm.now().Sub(gm.startTime)
+}
+
+func (gm *GameManager) resetStatistics() {
+	gm.pieceCounts = [len(tetriminoShapes)]int{}
+	gm.clearCounts = [clearTypeNum]int{}
+	gm.attack, gm.maxComboCount = 0, 0
+}
+
+// clear type of a lock down, -1 if nothing is cleared and no T-Spin made
+func (gm *GameManager) clearType(clearLineCount int) int {
+	switch {
+	case gm.tSpinFlag && gm.miniSpinFlag && clearLineCount <= 2:
+		return clearMiniTSpin + clearLineCount
+	case gm.tSpinFlag && clearLineCount <= 3:
+		return clearTSpin + clearLineCount
+	case clearLineCount > 0:
+		return clearSingle + clearLineCount - 1
+	}
+	return -1
+}
+
+// record the clear type and attack of a lock down
+func (gm *GameManager) recordClear(clearLineCount int) {
+	clearType := gm.clearType(clearLineCount)
+	if clearType < 0 {
+		return
+	}
+	gm.clearCounts[clearType]++
+	gm.attack += clearAttacks[clearType]
+	if clearLineCount == 0 {
+		return
+	}
+	if gm.comboCount > gm.maxComboCount {
+		gm.maxComboCount = gm.comboCount
+	}
+	if gm.comboCount < len(comboAttacks) {
+		gm.attack += comboAttacks[gm.comboCount]
+	} else {
+		gm.attack += comboAttacks[len(comboAttacks)-1]
+	}
+	if gm.backToBackFlag && (clearLineCount == 4 || gm.tSpinFlag) {
+		gm.attack++
+	}
+	for _, tile := range gm.playfield {
+		if tile != emptyTile {
+			return
+		}
+	}
+	gm.clearCounts[clearPerfect]++
+	gm.attack += clearAttacks[clearPerfect]
+}
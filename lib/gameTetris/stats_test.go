@@ -0,0 +1,33 @@
+package gameTetris
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameManager_Statistics(t *testing.T) {
+	p, err := LoadPuzzle("puzzles/01-tetris.txt")
+	assert.NoError(t, err)
+
+	inputCh := make(chan int, 10)
+	for _, op := range []int{rotateClockwise, moveRight, moveRight, moveRight, moveRight, hardDrop} {
+		inputCh <- op
+	}
+	g := newTestGameManager()
+	g.inputCh = inputCh
+	solved, err := g.PlayPuzzle(p)
+	assert.NoError(t, err)
+	assert.True(t, solved)
+
+	stats := g.Statistics()
+	assert.Equal(t, 4, stats.Lines)
+	assert.Equal(t, 1, stats.Pieces)
+	assert.Equal(t, 1, stats.PieceCounts["I"])
+	assert.Equal(t, 0, stats.PieceCounts["T"])
+	assert.Equal(t, 1, stats.ClearCounts["Tetris"])
+	assert.Equal(t, 1, stats.ClearCounts["Perfect Clear"])
+	assert.Equal(t, clearAttacks[clearTetris]+clearAttacks[clearPerfect], stats.Attack)
+	assert.True(t, stats.Time > 0)
+	assert.True(t, stats.PPS > 0)
+}
@@ -0,0 +1,282 @@
+// Package keymap maps termbox keys to game actions, so every game can be
+// played with the keys each player likes. Bindings are loaded from a config
+// file with one section per game:
+//
+//	# comment
+//	[tetris]
+//	preset = vim
+//	hardDrop = Space, Enter
+//
+//	[2048]
+//	up = Up, w, k
+//
+// A section starts from the default preset of the game, "preset" switches
+// to another preset and every other line replaces the keys of an action.
+// Keys are single characters or names such as Up, Space, Enter, Esc and F1.
+package keymap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nsf/termbox-go"
+)
+
+// DefaultPreset is the name of preset a config section starts from
+const DefaultPreset = "default"
+
+// Key is a termbox special key, or a rune if Ch is not 0
+type Key struct {
+	Key termbox.Key
+	Ch  rune
+}
+
+var keyNames = map[string]termbox.Key{
+	"Up":        termbox.KeyArrowUp,
+	"Down":      termbox.KeyArrowDown,
+	"Left":      termbox.KeyArrowLeft,
+	"Right":     termbox.KeyArrowRight,
+	"Space":     termbox.KeySpace,
+	"Enter":     termbox.KeyEnter,
+	"Esc":       termbox.KeyEsc,
+	"Tab":       termbox.KeyTab,
+	"Backspace": termbox.KeyBackspace2,
+	"Delete":    termbox.KeyDelete,
+	"Insert":    termbox.KeyInsert,
+	"Home":      termbox.KeyHome,
+	"End":       termbox.KeyEnd,
+	"PgUp":      termbox.KeyPgup,
+	"PgDn":      termbox.KeyPgdn,
+	"F1":        termbox.KeyF1,
+	"F2":        termbox.KeyF2,
+	"F3":        termbox.KeyF3,
+	"F4":        termbox.KeyF4,
+	"F5":        termbox.KeyF5,
+	"F6":        termbox.KeyF6,
+	"F7":        termbox.KeyF7,
+	"F8":        termbox.KeyF8,
+	"F9":        termbox.KeyF9,
+	"F10":       termbox.KeyF10,
+	"F11":       termbox.KeyF11,
+	"F12":       termbox.KeyF12,
+	"Comma":     0, // rune ',', separates keys in config
+}
+
+// ParseKey parse a single character or a key name (case insensitive)
+func ParseKey(s string) (Key, error) {
+	if runes := []rune(s); len(runes) == 1 {
+		return Key{Ch: runes[0]}, nil
+	}
+	for name, key := range keyNames {
+		if strings.EqualFold(name, s) {
+			if name == "Comma" {
+				return Key{Ch: ','}, nil
+			}
+			return Key{Key: key}, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown key %q", s)
+}
+
+// String is the name of key as written in config
+func (k Key) String() string {
+	if k.Ch == ',' {
+		return "Comma"
+	}
+	if k.Ch != 0 {
+		return string(k.Ch)
+	}
+	for name, key := range keyNames {
+		if key == k.Key && name != "Comma" {
+			return name
+		}
+	}
+	return fmt.Sprintf("Key(%d)", k.Key)
+}
+
+// Keymap binds keys to actions of a game, an action may have many keys
+// but a key triggers one action only
+type Keymap struct {
+	actions map[string][]Key
+	keys    map[Key]string
+}
+
+// New keymap from action names to key names
+func New(bindings map[string][]string) (*Keymap, error) {
+	km := &Keymap{actions: map[string][]Key{}, keys: map[Key]string{}}
+	// bind in a fixed order, so a key bound twice always ends up the same
+	actions := make([]string, 0, len(bindings))
+	for action := range bindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		keys, err := parseKeys(bindings[action])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", action, err)
+		}
+		km.Bind(action, keys...)
+	}
+	return km, nil
+}
+
+// MustNew is like New but panics on unknown key names, for presets
+func MustNew(bindings map[string][]string) *Keymap {
+	km, err := New(bindings)
+	if err != nil {
+		panic(err)
+	}
+	return km
+}
+
+// With returns a copy of km with some actions bound to other keys
+func (km *Keymap) With(bindings map[string][]string) *Keymap {
+	clone := km.Clone()
+	override := MustNew(bindings)
+	for action, keys := range override.actions {
+		clone.Bind(action, keys...)
+	}
+	return clone
+}
+
+func parseKeys(names []string) ([]Key, error) {
+	keys := make([]Key, 0, len(names))
+	for _, name := range names {
+		key, err := ParseKey(name)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Clone keymap
+func (km *Keymap) Clone() *Keymap {
+	clone := &Keymap{actions: make(map[string][]Key, len(km.actions)), keys: make(map[Key]string, len(km.keys))}
+	for action, keys := range km.actions {
+		clone.actions[action] = append([]Key(nil), keys...)
+	}
+	for key, action := range km.keys {
+		clone.keys[key] = action
+	}
+	return clone
+}
+
+// Bind replace keys of action, the keys are taken from other actions
+func (km *Keymap) Bind(action string, keys ...Key) {
+	for _, key := range km.actions[action] {
+		delete(km.keys, key)
+	}
+	km.actions[action] = nil
+	for _, key := range keys {
+		if other, ok := km.keys[key]; ok && other != action {
+			km.actions[other] = removeKey(km.actions[other], key)
+		}
+		if km.keys[key] != action {
+			km.actions[action] = append(km.actions[action], key)
+		}
+		km.keys[key] = action
+	}
+}
+
+func removeKey(keys []Key, key Key) []Key {
+	left := keys[:0]
+	for _, k := range keys {
+		if k != key {
+			left = append(left, k)
+		}
+	}
+	return left
+}
+
+// Keys bound to action
+func (km *Keymap) Keys(action string) []Key {
+	return append([]Key(nil), km.actions[action]...)
+}
+
+// Action triggered by a termbox key event
+func (km *Keymap) Action(ev termbox.Event) (string, bool) {
+	if ev.Type != termbox.EventKey {
+		return "", false
+	}
+	key := Key{Key: ev.Key}
+	if ev.Ch != 0 {
+		key = Key{Ch: ev.Ch}
+	}
+	action, ok := km.keys[key]
+	return action, ok
+}
+
+// Load keymap of section (a game) from config, starting from its
+// default preset. Actions not in the default preset are rejected.
+func Load(r io.Reader, section string, presets map[string]*Keymap) (*Keymap, error) {
+	km := presets[DefaultPreset].Clone()
+	current := ""
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if current != section {
+			continue
+		}
+		sep := strings.Index(line, "=")
+		if sep < 0 {
+			return nil, fmt.Errorf("line %d: invalid binding %q", lineNum, line)
+		}
+		name, value := strings.TrimSpace(line[:sep]), strings.TrimSpace(line[sep+1:])
+		if name == "preset" {
+			preset, ok := presets[value]
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown preset %q", lineNum, value)
+			}
+			km = preset.Clone()
+			continue
+		}
+		if _, ok := presets[DefaultPreset].actions[name]; !ok {
+			return nil, fmt.Errorf("line %d: unknown action %q", lineNum, name)
+		}
+		var names []string
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				names = append(names, field)
+			}
+		}
+		keys, err := parseKeys(names)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		km.Bind(name, keys...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+// LoadFile is like Load, a missing file gives the default preset
+func LoadFile(path, section string, presets map[string]*Keymap) (*Keymap, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return presets[DefaultPreset].Clone(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	km, err := Load(f, section, presets)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return km, nil
+}
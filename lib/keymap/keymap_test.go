@@ -0,0 +1,116 @@
+package keymap
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPresets = map[string]*Keymap{
+	DefaultPreset: MustNew(map[string][]string{
+		"left":  {"Left"},
+		"right": {"Right"},
+		"drop":  {"Space", "Enter"},
+	}),
+	"vim": MustNew(map[string][]string{
+		"left":  {"h"},
+		"right": {"l"},
+		"drop":  {"j"},
+	}),
+}
+
+func keyEvent(key termbox.Key, ch rune) termbox.Event {
+	return termbox.Event{Type: termbox.EventKey, Key: key, Ch: ch}
+}
+
+func TestParseKey(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  Key
+	}{
+		{"x", Key{Ch: 'x'}},
+		{"X", Key{Ch: 'X'}},
+		{"space", Key{Key: termbox.KeySpace}},
+		{"Up", Key{Key: termbox.KeyArrowUp}},
+		{"F1", Key{Key: termbox.KeyF1}},
+		{"Comma", Key{Ch: ','}},
+	}
+	for _, testCase := range testCases {
+		key, err := ParseKey(testCase.name)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.key, key)
+	}
+	_, err := ParseKey("Hyper")
+	assert.Error(t, err)
+	assert.Equal(t, "Space", Key{Key: termbox.KeySpace}.String())
+	assert.Equal(t, "q", Key{Ch: 'q'}.String())
+}
+
+func TestKeymap_Bind(t *testing.T) {
+	km := testPresets[DefaultPreset].Clone()
+	action, ok := km.Action(keyEvent(termbox.KeyEnter, 0))
+	assert.True(t, ok)
+	assert.Equal(t, "drop", action)
+
+	// a key moves to its new action
+	km.Bind("left", Key{Key: termbox.KeyArrowLeft}, Key{Key: termbox.KeyEnter})
+	action, _ = km.Action(keyEvent(termbox.KeyEnter, 0))
+	assert.Equal(t, "left", action)
+	assert.Equal(t, []Key{{Key: termbox.KeySpace}}, km.Keys("drop"))
+
+	_, ok = km.Action(keyEvent(0, 'z'))
+	assert.False(t, ok)
+	_, ok = km.Action(termbox.Event{Type: termbox.EventResize})
+	assert.False(t, ok)
+
+	// presets are not touched
+	action, _ = testPresets[DefaultPreset].Action(keyEvent(termbox.KeyEnter, 0))
+	assert.Equal(t, "drop", action)
+
+	with := km.With(map[string][]string{"right": {"d", "Right"}})
+	action, _ = with.Action(keyEvent(0, 'd'))
+	assert.Equal(t, "right", action)
+	_, ok = km.Action(keyEvent(0, 'd'))
+	assert.False(t, ok)
+}
+
+func TestLoad(t *testing.T) {
+	config := `
+# comment
+[other]
+left = a
+
+[game]
+preset = vim
+drop = Space, s
+`
+	km, err := Load(strings.NewReader(config), "game", testPresets)
+	assert.NoError(t, err)
+	action, _ := km.Action(keyEvent(0, 'h'))
+	assert.Equal(t, "left", action)
+	action, _ = km.Action(keyEvent(termbox.KeySpace, 0))
+	assert.Equal(t, "drop", action)
+	_, ok := km.Action(keyEvent(0, 'j'))
+	assert.False(t, ok, "j is replaced")
+	_, ok = km.Action(keyEvent(0, 'a'))
+	assert.False(t, ok, "other section is skipped")
+
+	for _, invalid := range []string{
+		"[game]\nfly = f",
+		"[game]\nleft = Hyper",
+		"[game]\npreset = emacs",
+		"[game]\nleft",
+	} {
+		_, err := Load(strings.NewReader(invalid), "game", testPresets)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	km, err := LoadFile(filepath.Join(t.TempDir(), "missing.conf"), "game", testPresets)
+	assert.NoError(t, err)
+	assert.Equal(t, testPresets[DefaultPreset].Keys("drop"), km.Keys("drop"))
+}
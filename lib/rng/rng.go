@@ -0,0 +1,49 @@
+// Package rng provides a pseudo-random source whose state can be saved and
+// restored, so games can be snapshotted, undone and replayed.
+package rng
+
+import "math/rand"
+
+// Source is a splitmix64 generator implementing rand.Source64
+type Source struct {
+	state uint64
+}
+
+// NewSource returns a source seeded with seed
+func NewSource(seed int64) *Source {
+	return &Source{state: uint64(seed)}
+}
+
+// New returns a rand.Rand drawing from src
+func New(src *Source) *rand.Rand {
+	return rand.New(src)
+}
+
+// Seed the source
+func (s *Source) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// Uint64 returns next pseudo-random value
+func (s *Source) Uint64() uint64 {
+	s.state += 0x9E3779B97F4A7C15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// Int63 returns next pseudo-random non-negative value
+func (s *Source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// State of the source, restore it with SetState
+func (s *Source) State() uint64 {
+	return s.state
+}
+
+// SetState restore a state returned by State
+func (s *Source) SetState(state uint64) {
+	s.state = state
+}
@@ -0,0 +1,31 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource_State(t *testing.T) {
+	src := NewSource(42)
+	r := New(src)
+	r.Intn(100)
+	state := src.State()
+	want := []int{r.Intn(100), r.Intn(100), r.Intn(100)}
+
+	src.SetState(state)
+	assert.Equal(t, want, []int{r.Intn(100), r.Intn(100), r.Intn(100)})
+
+	other := NewSource(42)
+	other.SetState(state)
+	assert.Equal(t, want, []int{New(other).Intn(100), New(other).Intn(100), New(other).Intn(100)})
+}
+
+func TestSource_Seed(t *testing.T) {
+	a, b := NewSource(7), NewSource(8)
+	assert.NotEqual(t, a.Uint64(), b.Uint64())
+	a.Seed(8)
+	b.Seed(8)
+	assert.Equal(t, a.Uint64(), b.Uint64())
+	assert.True(t, a.Int63() >= 0)
+}
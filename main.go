@@ -4,13 +4,254 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/game2048"
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/game2048/solver"
 	"github.com/SpicyChickenFLY/tiny-games-go/lib/gameBullsAndCows"
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/gameTetris"
 )
 
+// path of a file in config directory, empty if there is none
+func configPath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "tiny-games-go", name)
+}
+
+// play 2048 and keep the records of name, one per game played
+func run2048(name string, opts game2048.Options) {
+	game2048.UseAdvisor(solver.New(0))
+	game2048.SaveReplays(configPath("2048-replay.jsonl"))
+	// records come back even if the replay could not be saved
+	records, runErr := game2048.Run(name, opts)
+	best := 0
+	for _, record := range records {
+		fmt.Printf("%s's final score is: %d (seed %d)\n", name, record.Score, record.Seed)
+		if record.Score > best {
+			best = record.Score
+		}
+	}
+	if recordsPath := configPath("2048-records.json"); recordsPath != "" && len(records) > 0 {
+		prevBest, found, err := game2048.BestRecord(recordsPath, name)
+		for i := 0; err == nil && i < len(records); i++ {
+			err = game2048.AppendRecord(recordsPath, records[i])
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if found && prevBest.Score > best {
+			fmt.Printf("%s's best score is: %d\n", name, prevBest.Score)
+		}
+	}
+	if runErr != nil {
+		fmt.Println(runErr)
+		os.Exit(1)
+	}
+}
+
+// options2048 of command line args [name] [width] [height] [difficult]
+// [target] [undos] and env, a difficult other than 2 replaces the classic
+// spawn by 2^1 up to 2^difficult of equal chance
+func options2048(args []string, getenv func(string) string) (string, game2048.Options, error) {
+	name, opts := "player", game2048.DefaultOptions()
+	if len(args) > 0 {
+		name = args[0]
+	}
+	for i, arg := range []*int{&opts.Width, &opts.Height, &opts.Difficult, &opts.Target, &opts.Undos} {
+		if len(args) <= 1+i {
+			break
+		}
+		n, err := strconv.Atoi(args[1+i])
+		if err != nil {
+			return "", opts, fmt.Errorf("width, height, difficult, target and undos should be numbers")
+		}
+		*arg = n
+	}
+	if opts.Difficult != game2048.DefaultOptions().Difficult {
+		opts.Spawn = game2048.SpawnPolicy{}
+	}
+	// GAME2048_SPAWN=hard (or adversarial) changes new numbers
+	if spawn := getenv("GAME2048_SPAWN"); spawn != "" {
+		policy, err := game2048.SpawnPreset(spawn)
+		if err != nil {
+			return "", opts, err
+		}
+		opts.Spawn = policy
+	}
+	// GAME2048_SEED replays the board of a record
+	if seed := getenv("GAME2048_SEED"); seed != "" {
+		n, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			return "", opts, fmt.Errorf("GAME2048_SEED should be a number")
+		}
+		opts.Seed = n
+	}
+	return name, opts, nil
+}
+
 func main() {
+	if keysPath := configPath("keys.conf"); keysPath != "" {
+		for _, load := range []func(string) error{
+			gameTetris.LoadKeymap,
+			game2048.LoadKeymap,
+			gameBullsAndCows.LoadKeymap,
+		} {
+			if err := load(keysPath); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+	}
+	// TETRIS_SPECTATE=tcp:127.0.0.1:7777 (or unix:/path/to/sock) lets
+	// others watch tetris games with "spectate tcp 127.0.0.1:7777"
+	if stream := os.Getenv("TETRIS_SPECTATE"); stream != "" {
+		sep := strings.Index(stream, ":")
+		if sep < 0 {
+			fmt.Println("TETRIS_SPECTATE should be network:address")
+			os.Exit(1)
+		}
+		closer, err := gameTetris.ServeSpectators(stream[:sep], stream[sep+1:])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer closer.Close()
+	}
+	// TETRIS_BOARD=big (or narrow, tall) plays tetris on another board
+	if board := os.Getenv("TETRIS_BOARD"); board != "" {
+		if err := gameTetris.UseBoard(board); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "spectate":
+			if len(os.Args) < 4 {
+				fmt.Println("usage: spectate <tcp|unix> <address>")
+				os.Exit(1)
+			}
+			if err := gameTetris.Spectate(os.Args[2], os.Args[3]); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		case "2048":
+			// 2048 [name] [width] [height] [difficult] [target] [undos]
+			name, opts, err := options2048(os.Args[2:], os.Getenv)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			run2048(name, opts)
+			return
+		case "2048-daily":
+			// 2048-daily [name], the same board for everyone today
+			name := "player"
+			if len(os.Args) > 2 {
+				name = os.Args[2]
+			}
+			today := time.Now()
+			fmt.Printf("daily challenge of %s\n", today.UTC().Format("2006-01-02"))
+			run2048(name, game2048.DailyOptions(today))
+			return
+		case "2048-replay":
+			// 2048-replay [path], step through the last game by default
+			path := configPath("2048-replay.jsonl")
+			if len(os.Args) > 2 {
+				path = os.Args[2]
+			}
+			if err := game2048.RunReplay(path); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		case "2048-bench":
+			// 2048-bench [games] [depth], solver on seeded classic games
+			games, depth := 10, solver.DefaultDepth
+			for i, arg := range []*int{&games, &depth} {
+				if len(os.Args) <= 2+i {
+					break
+				}
+				n, err := strconv.Atoi(os.Args[2+i])
+				if err != nil {
+					fmt.Println("games and depth should be numbers")
+					os.Exit(1)
+				}
+				*arg = n
+			}
+			start := time.Now()
+			result, err := solver.Benchmark(solver.New(depth), game2048.DefaultOptions(), games)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("%d games at depth %d in %v\n", result.Games, depth, time.Since(start).Round(time.Millisecond))
+			fmt.Printf("win rate: %.1f%%, average score: %.1f, best tile: %d\n",
+				result.WinRate*100, result.AverageScore, result.BestTile)
+			return
+		case "tetris":
+			score, err := gameTetris.Run(0, configPath("tetris-save.json"))
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Println("your final score is: ", score)
+			return
+		case "marathon":
+			m := gameTetris.DefaultMarathon()
+			if len(os.Args) > 2 {
+				level, err := strconv.Atoi(os.Args[2])
+				if err != nil {
+					fmt.Println("start level should be a number")
+					os.Exit(1)
+				}
+				m.StartLevel = level
+			}
+			m.Endless = len(os.Args) > 3 && os.Args[3] == "endless"
+			results, err := gameTetris.RunMarathon(m, 0)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Println("your final score is: ", results.Score)
+			return
+		case "practice":
+			undoLimit := -1
+			if len(os.Args) > 2 {
+				n, err := strconv.Atoi(os.Args[2])
+				if err != nil {
+					fmt.Println("undo limit should be a number")
+					os.Exit(1)
+				}
+				undoLimit = n
+			}
+			if err := gameTetris.RunPractice(undoLimit); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		case "puzzle":
+			// puzzle [dir], the bundled puzzles by default
+			dir := ""
+			if len(os.Args) > 2 {
+				dir = os.Args[2]
+			}
+			if err := gameTetris.RunPuzzles(dir); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// if err := termbox.Init(); err != nil {
 	// 	panic(err)
 	// }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/SpicyChickenFLY/tiny-games-go/lib/game2048"
+	"github.com/stretchr/testify/assert"
+)
+
+func noEnv(string) string { return "" }
+
+func TestOptions2048(t *testing.T) {
+	name, opts, err := options2048(nil, noEnv)
+	assert.Nil(t, err)
+	assert.Equal(t, "player", name)
+	assert.Equal(t, game2048.DefaultOptions(), opts)
+
+	// difficult reaches the game
+	name, opts, err = options2048([]string{"tester", "4", "4", "3"}, noEnv)
+	assert.Nil(t, err)
+	assert.Equal(t, "tester", name)
+	g, err := game2048.New(opts)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 1, 1}, g.SpawnPolicy().Weights)
+
+	// the default difficult keeps the classic spawn for target and undos
+	_, opts, err = options2048([]string{"tester", "4", "4", "2", "4096", "3"}, noEnv)
+	assert.Nil(t, err)
+	assert.Equal(t, game2048.DefaultOptions().Spawn, opts.Spawn)
+	assert.Equal(t, 4096, opts.Target)
+	assert.Equal(t, 3, opts.Undos)
+
+	_, opts, _ = options2048([]string{"tester", "4", "4", "9"}, noEnv)
+	_, err = game2048.New(opts)
+	assert.NotNil(t, err, "difficult is validated")
+
+	// a spawn preset wins over difficult
+	_, opts, err = options2048([]string{"tester", "4", "4", "3"}, func(key string) string {
+		return map[string]string{"GAME2048_SPAWN": "hard", "GAME2048_SEED": "7"}[key]
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{3, 1}, opts.Spawn.Weights)
+	assert.Equal(t, int64(7), opts.Seed)
+
+	_, _, err = options2048([]string{"tester", "four"}, noEnv)
+	assert.NotNil(t, err)
+}